@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"time"
 
 	"github.com/geraldiaditya/ratix-backend/internal/config"
 	"github.com/geraldiaditya/ratix-backend/internal/infrastructure"
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
 	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
 	cinemaHandler "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/handler"
 	cinemaRepository "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/repository"
 	cinemaService "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/service"
+	jobDomain "github.com/geraldiaditya/ratix-backend/internal/modules/job/domain"
+	jobHandler "github.com/geraldiaditya/ratix-backend/internal/modules/job/handler"
+	jobRepository "github.com/geraldiaditya/ratix-backend/internal/modules/job/repository"
+	jobService "github.com/geraldiaditya/ratix-backend/internal/modules/job/service"
 	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
 	movieHandler "github.com/geraldiaditya/ratix-backend/internal/modules/movie/handler"
 	movieRepository "github.com/geraldiaditya/ratix-backend/internal/modules/movie/repository"
 	movieService "github.com/geraldiaditya/ratix-backend/internal/modules/movie/service"
+	paymentDomain "github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	paymentGateway "github.com/geraldiaditya/ratix-backend/internal/modules/payment/gateway"
+	paymentHandler "github.com/geraldiaditya/ratix-backend/internal/modules/payment/handler"
+	paymentRepository "github.com/geraldiaditya/ratix-backend/internal/modules/payment/repository"
+	paymentService "github.com/geraldiaditya/ratix-backend/internal/modules/payment/service"
+	pricingDomain "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	pricingHandler "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/handler"
+	pricingRepository "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/repository"
+	pricingService "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/service"
+	reviewDomain "github.com/geraldiaditya/ratix-backend/internal/modules/review/domain"
+	reviewHandler "github.com/geraldiaditya/ratix-backend/internal/modules/review/handler"
+	reviewRepository "github.com/geraldiaditya/ratix-backend/internal/modules/review/repository"
+	reviewService "github.com/geraldiaditya/ratix-backend/internal/modules/review/service"
 	ticketDomain "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
 	ticketHandler "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/handler"
 	ticketRepository "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/repository"
@@ -22,6 +42,11 @@ import (
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/handler"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/repository"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/service"
+	watchlistDomain "github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+	watchlistHandler "github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/handler"
+	watchlistRepository "github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/repository"
+	watchlistSender "github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/sender"
+	watchlistService "github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/service"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
@@ -41,9 +66,16 @@ func main() {
 		// We migrate Genre first because Movie depends on Genre.
 		if err := db.AutoMigrate(
 			&userDomain.User{},
+			&userDomain.RefreshToken{},
 			&cinemaDomain.Cinema{},
 			&cinemaDomain.Theater{},
+			&cinemaDomain.TheaterSeat{},
+			&cinemaDomain.CinemaStaff{},
+			&cinemaDomain.ScannerDevice{},
 			&movieDomain.Genre{},
+			&pricingDomain.PriceRule{},
+			&pricingDomain.Holiday{},
+			&jobDomain.JobRun{},
 		); err != nil {
 			log.Printf("Warning: Failed to auto migrate phase 1: %v", err)
 		}
@@ -54,12 +86,51 @@ func main() {
 		db.Model(&cinemaDomain.Cinema{}).Count(&cinemaCount)
 		if cinemaCount == 0 {
 			log.Println("Seeding dummy cinema data...")
-			jakartaCinema := cinemaDomain.Cinema{Name: "Cinema XXI, Grand Indonesia", City: "Jakarta", Address: "Jl. M.H. Thamrin No.1", BasePrice: 50000}
-			bandungCinema := cinemaDomain.Cinema{Name: "CGV, Paris Van Java", City: "Bandung", Address: "Jl. Sukajadi No.131-139", BasePrice: 35000}
+			jakartaLat, jakartaLng := -6.1950, 106.8210
+			bandungLat, bandungLng := -6.8890, 107.5960
+			jakartaCinema := cinemaDomain.Cinema{Name: "Cinema XXI, Grand Indonesia", City: "Jakarta", Address: "Jl. M.H. Thamrin No.1", BasePrice: 50000, TimeZone: "Asia/Jakarta", Latitude: &jakartaLat, Longitude: &jakartaLng}
+			bandungCinema := cinemaDomain.Cinema{Name: "CGV, Paris Van Java", City: "Bandung", Address: "Jl. Sukajadi No.131-139", BasePrice: 35000, TimeZone: "Asia/Jakarta", Latitude: &bandungLat, Longitude: &bandungLng}
 			db.Create(&jakartaCinema) // ID likely 1
 			db.Create(&bandungCinema) // ID likely 2
 		}
 
+		var theaterCount int64
+		db.Model(&cinemaDomain.Theater{}).Count(&theaterCount)
+		if theaterCount == 0 {
+			log.Println("Seeding dummy theater data...")
+			var cinemas []cinemaDomain.Cinema
+			db.Find(&cinemas)
+			for _, c := range cinemas {
+				db.Create(&cinemaDomain.Theater{CinemaID: c.ID, Name: "Studio 1", Type: "Regular"})
+			}
+		}
+
+		var seatCount int64
+		db.Model(&cinemaDomain.TheaterSeat{}).Count(&seatCount)
+		if seatCount == 0 {
+			log.Println("Seeding default seat maps...")
+			var theaters []cinemaDomain.Theater
+			db.Find(&theaters)
+			for _, t := range theaters {
+				seats := cinemaDomain.DefaultSeatMap(t.ID)
+				db.Create(&seats)
+			}
+		}
+
+		var ruleCount int64
+		db.Model(&pricingDomain.PriceRule{}).Count(&ruleCount)
+		if ruleCount == 0 {
+			log.Println("Seeding default price rules...")
+			db.Create(&pricingDomain.PriceRule{
+				Name:       "Premium seat surcharge",
+				SeatType:   cinemaDomain.SeatTypePremium,
+				Adjustment: pricingDomain.AdjustAdd,
+				Amount:     25000,
+				Priority:   100,
+				Active:     true,
+			})
+		}
+
 		var genreCount int64
 		db.Model(&movieDomain.Genre{}).Count(&genreCount)
 		if genreCount == 0 {
@@ -75,8 +146,18 @@ func main() {
 		if err := db.AutoMigrate(
 			&movieDomain.Movie{},
 			&movieDomain.CastMember{},
+			&movieDomain.MoviePoster{},
 			&movieDomain.Showtime{},
 			&ticketDomain.Ticket{},
+			&ticketDomain.TicketSeat{},
+			&ticketDomain.SeatHold{},
+			&ticketDomain.Admission{},
+			&paymentDomain.Payment{},
+			&reviewDomain.Review{},
+			&reviewDomain.ReviewVote{},
+			&watchlistDomain.WatchlistItem{},
+			&watchlistDomain.OnSaleAlert{},
+			&watchlistDomain.Notification{},
 		); err != nil {
 			log.Printf("Warning: Failed to auto migrate phase 2: %v", err)
 		}
@@ -85,21 +166,81 @@ func main() {
 		// User Module
 		validate := validator.New()
 		userRepo := repository.NewPostgresUserRepository(db)
-		userService := service.NewUserService(userRepo, cfg.JWTSecret)
+		refreshTokenRepo := repository.NewPostgresRefreshTokenRepository(db)
+		userService := service.NewUserService(userRepo, refreshTokenRepo, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
 		userHandler := handler.NewUserHandler(userService, validate)
 
+		if cfg.BootstrapAdminEmail != "" {
+			if err := userService.EnsureSuperAdmin(cfg.BootstrapAdminEmail); err != nil {
+				log.Printf("Warning: Failed to promote bootstrap admin %s: %v", cfg.BootstrapAdminEmail, err)
+			}
+		}
+
+		// Pricing Module: seat layouts, bookings and listings all quote through it
+		pricingRepo := pricingRepository.NewPostgresPricingRepository(db)
+		pricingService := pricingService.NewPricingService(pricingRepo)
+		pricingHandler := pricingHandler.NewPricingHandler(pricingService, validate)
+
 		movieRepo := movieRepository.NewPostgresMovieRepository(db)
-		movieService := movieService.NewMovieService(movieRepo)
-		movieHandler := movieHandler.NewMovieHandler(movieService)
+		// Needed early: MovieService checks theaters when scheduling showtimes
+		cinemaRepo := cinemaRepository.NewPostgresCinemaRepository(db)
 
-		// Initialize TicketRepo first as CinemaService needs it
+		movieService := movieService.NewMovieService(movieRepo, cinemaRepo, pricingService, cfg.ShowtimeBuffer)
+		movieHandler := movieHandler.NewMovieHandler(movieService, validate)
+
+		// Initialize TicketRepo and SeatHoldRepo first as CinemaService needs them
 		ticketRepo := ticketRepository.NewPostgresTicketRepository(db)
-		ticketService := ticketService.NewTicketService(ticketRepo)
-		ticketHandler := ticketHandler.NewTicketHandler(ticketService)
+		seatHoldRepo := ticketRepository.NewPostgresSeatHoldRepository(db)
 
-		cinemaRepo := cinemaRepository.NewPostgresCinemaRepository(db)
-		cinemaService := cinemaService.NewCinemaService(cinemaRepo, ticketRepo)
-		cinemaHandler := cinemaHandler.NewCinemaHandler(cinemaService)
+		cinemaService := cinemaService.NewCinemaService(cinemaRepo, ticketRepo, seatHoldRepo, movieRepo, pricingService)
+		cinemaHandler := cinemaHandler.NewCinemaHandler(cinemaService, validate)
+
+		// Payment Module
+		if cfg.Payment.Provider != paymentGateway.FakeName {
+			log.Fatalf("Unsupported payment provider: %s", cfg.Payment.Provider)
+		}
+		paymentRepo := paymentRepository.NewPostgresPaymentRepository(db)
+		paymentService := paymentService.NewPaymentService(paymentRepo, paymentGateway.NewFakeGateway(cfg.Payment.WebhookSecret), cfg.Payment.Currency, cfg.Payment.Timeout)
+		paymentHandler := paymentHandler.NewPaymentHandler(paymentService, validate)
+
+		// TicketService books seats against the CinemaService seat layout
+		refundPolicy := ticketDomain.RefundPolicy{
+			FullRefundBefore:     cfg.Cancellation.FullRefundBefore,
+			PartialRefundPercent: cfg.Cancellation.PartialRefundPercent,
+			NoRefundWithin:       cfg.Cancellation.NoRefundWithin,
+		}
+		qrSigner := ticketDomain.NewQRSigner(cfg.QRSigningSecret)
+		receiptIssuer := ticketDomain.ReceiptIssuer{
+			Name:    cfg.Receipt.IssuerName,
+			Address: cfg.Receipt.IssuerAddress,
+			TaxID:   cfg.Receipt.IssuerTaxID,
+			TaxRate: cfg.Receipt.TaxRate,
+		}
+		ticketSvc := ticketService.NewTicketService(ticketRepo, movieRepo, cinemaService, paymentService, refundPolicy, qrSigner, receiptIssuer)
+
+		// Seat holds expire on their own; the sweeper just clears out old rows
+		seatHoldService := ticketService.NewSeatHoldService(seatHoldRepo, cinemaService, ticketSvc, cfg.SeatHoldTTL)
+		seatHoldHandler := ticketHandler.NewSeatHoldHandler(seatHoldService, validate)
+		seatHoldService.StartSweeper(context.Background(), time.Minute)
+
+		// Gate scanners verify the same signed QR codes tickets are issued with
+		admissionWindow := ticketDomain.AdmissionWindow{OpensBefore: cfg.Checkin.OpensBefore, ClosesAfter: cfg.Checkin.ClosesAfter}
+		checkinService := ticketService.NewCheckinService(ticketRepo, movieRepo, cinemaService, qrSigner, admissionWindow)
+		checkinHandler := ticketHandler.NewCheckinHandler(checkinService, validate)
+
+		ticketHandler := ticketHandler.NewTicketHandler(ticketSvc, validate)
+
+		// Reviews keep each movie's rating up to date as they are published
+		reviewPrior := reviewDomain.RatingPrior{Mean: cfg.Review.PriorMean, Weight: cfg.Review.PriorWeight}
+		reviewService := reviewService.NewReviewService(reviewRepository.NewPostgresReviewRepository(db), reviewPrior, cfg.Review.ReportThreshold)
+		reviewHandler := reviewHandler.NewReviewHandler(reviewService, validate)
+
+		// Watchlist Module: on-sale alerts are emailed and kept as in-app notifications
+		if cfg.Notify.EmailSender != watchlistSender.LogName {
+			log.Fatalf("Unsupported email sender: %s", cfg.Notify.EmailSender)
+		}
+		watchlistService := watchlistService.NewWatchlistService(watchlistRepository.NewPostgresWatchlistRepository(db), movieRepo, cinemaRepo, watchlistSender.NewLogSender(cfg.Notify.EmailFrom))
+		watchlistHandler := watchlistHandler.NewWatchlistHandler(watchlistService, validate)
 
 		// Seeder Phase 2: Movies & Tickets
 		var count int64
@@ -112,37 +253,43 @@ func main() {
 			db.Where("name = ?", "Action").First(&action)
 			db.Where("name = ?", "Fantasy").First(&fantasy)
 
+			// Seeded showtimes play in the first cinema's first theater
+			var studio cinemaDomain.Theater
+			db.Where("cinema_id = ?", 1).Order("id").First(&studio)
+
 			// Movie 1: The Crimson Blade
 			movie1 := movieDomain.Movie{
-				Title:       "The Crimson Blade",
-				Description: "A legendary warrior awakens to defend his kingdom from an ancient evil.",
-				Duration:    135,
-				Rating:      8.9,
-				PosterURL:   "https://example.com/poster1.jpg",
-				ReleaseDate: time.Now(),
-				Status:      "now_showing",
-				Genres:      []movieDomain.Genre{action, fantasy},
+				Title:            "The Crimson Blade",
+				Description:      "A legendary warrior awakens to defend his kingdom from an ancient evil.",
+				Duration:         135,
+				Rating:           8.9,
+				AgeCertification: movieDomain.Certification13Plus,
+				PosterURL:        "https://example.com/poster1.jpg",
+				ReleaseDate:      time.Now(),
+				Status:           "now_showing",
+				Genres:           []movieDomain.Genre{action, fantasy},
 				Cast: []movieDomain.CastMember{
 					{Name: "John Smith", Role: "Actor", CharacterName: "Blade", PhotoURL: "https://example.com/pro1.jpg"},
 					{Name: "Alan Smithee", Role: "Director"},
 				},
 				Showtimes: []movieDomain.Showtime{
-					{StartTime: time.Now().Add(1 * time.Hour)},
-					{StartTime: time.Now().Add(4 * time.Hour)},
+					{StartTime: time.Now().Add(1 * time.Hour), TheaterID: &studio.ID},
+					{StartTime: time.Now().Add(4 * time.Hour), TheaterID: &studio.ID},
 				},
 			}
 			db.Create(&movie1)
 
 			// Movie 2: Echoes of Tomorrow
 			movie2 := movieDomain.Movie{
-				Title:       "Echoes of Tomorrow",
-				Description: "A sci-fi thriller about time travel.",
-				Duration:    120,
-				Rating:      9.1,
-				PosterURL:   "https://example.com/poster2.jpg",
-				ReleaseDate: time.Now().AddDate(0, 0, 7),
-				Status:      "coming_soon",
-				Genres:      []movieDomain.Genre{fantasy},
+				Title:            "Echoes of Tomorrow",
+				Description:      "A sci-fi thriller about time travel.",
+				Duration:         120,
+				Rating:           9.1,
+				AgeCertification: movieDomain.Certification17Plus,
+				PosterURL:        "https://example.com/poster2.jpg",
+				ReleaseDate:      time.Now().AddDate(0, 0, 7),
+				Status:           "coming_soon",
+				Genres:           []movieDomain.Genre{fantasy},
 			}
 			db.Create(&movie2)
 			log.Println("Seeding complete.")
@@ -159,7 +306,7 @@ func main() {
 					UserID:      1,
 					MovieID:     m1.ID,
 					ShowtimeID:  1,
-					BookingCode: "BOOK-12345",
+					BookingCode: seedBookingCode(),
 					Seats:       "G14, G15",
 					CinemaName:  "Cinema XXI, Mall Grand Indonesia",
 					TheaterName: "Studio 1",
@@ -173,12 +320,12 @@ func main() {
 					UserID:      1,
 					MovieID:     m1.ID,
 					ShowtimeID:  1,
-					BookingCode: "BOOK-67890",
+					BookingCode: seedBookingCode(),
 					Seats:       "A1",
 					CinemaName:  "CGV, Central Park",
 					TheaterName: "Velvet Class",
 					Price:       150000,
-					Status:      "history",
+					Status:      ticketDomain.StatusCompleted,
 					CreatedAt:   time.Now().AddDate(0, -1, 0),
 				}
 				db.Create(&ticket2)
@@ -186,12 +333,52 @@ func main() {
 			}
 		}
 
+		// Search vector and indexes; also picks up movies seeded above
+		if err := movieRepo.EnsureSearchIndex(); err != nil {
+			log.Printf("Warning: Failed to set up movie search: %v", err)
+		}
+
+		// Migrate legacy "G14, G15" seat strings into ticket_seats
+		if created, skipped, err := ticketRepo.BackfillSeats(); err != nil {
+			log.Printf("Warning: Failed to backfill ticket seats: %v", err)
+		} else if created > 0 || skipped > 0 {
+			log.Printf("Backfilled %d ticket seats (%d skipped)", created, skipped)
+		}
+
+		// Older seeds wrote a "history" status that no query matches
+		if result := db.Model(&ticketDomain.Ticket{}).Where("status = ?", "history").Update("status", ticketDomain.StatusCompleted); result.Error != nil {
+			log.Printf("Warning: Failed to migrate history tickets: %v", result.Error)
+		} else if result.RowsAffected > 0 {
+			log.Printf("Migrated %d history tickets to completed", result.RowsAffected)
+		}
+
+		// Background jobs; safe to run on every replica
+		instance, _ := os.Hostname()
+		scheduler := jobService.NewScheduler(jobRepository.NewPostgresJobRunRepository(db), instance)
+		scheduler.Register(jobDomain.Job{Name: "complete_tickets", Interval: 5 * time.Minute, Run: ticketSvc.CompleteFinishedTickets})
+		scheduler.Register(jobDomain.Job{Name: "expire_unpaid_bookings", Interval: time.Minute, Run: paymentService.ExpireStalePayments})
+		scheduler.Register(jobDomain.Job{Name: "release_movies", Interval: time.Hour, Run: movieService.ReleaseDueMovies})
+		scheduler.Register(jobDomain.Job{Name: "fetch_posters", Interval: 10 * time.Minute, Run: movieService.FetchPosters})
+		scheduler.Register(jobDomain.Job{Name: "notify_tickets_on_sale", Interval: time.Minute, Run: watchlistService.NotifyTicketsOnSale})
+		scheduler.Start(context.Background())
+		jobHandler := jobHandler.NewJobHandler(scheduler)
+
 		// 4. Setup Fiber App
 		app := fiber.New()
-		userHandler.RegisterRoutes(app)
-		movieHandler.RegisterRoutes(app)
-		ticketHandler.RegisterRoutes(app)
-		cinemaHandler.RegisterRoutes(app)
+		authMiddleware := middleware.JWTAuth(cfg.JWTSecret)
+		optionalAuthMiddleware := middleware.OptionalJWTAuth(cfg.JWTSecret)
+
+		userHandler.RegisterRoutes(app, authMiddleware)
+		movieHandler.RegisterRoutes(app, authMiddleware)
+		reviewHandler.RegisterRoutes(app, authMiddleware)
+		watchlistHandler.RegisterRoutes(app, authMiddleware)
+		ticketHandler.RegisterRoutes(app, authMiddleware)
+		seatHoldHandler.RegisterRoutes(app, authMiddleware)
+		checkinHandler.RegisterRoutes(app)
+		cinemaHandler.RegisterRoutes(app, authMiddleware, optionalAuthMiddleware)
+		pricingHandler.RegisterRoutes(app, authMiddleware)
+		paymentHandler.RegisterRoutes(app, authMiddleware)
+		jobHandler.RegisterRoutes(app, authMiddleware)
 
 		// 5. Start Server
 		log.Printf("Starting server on port %s", cfg.ServerPort)
@@ -200,3 +387,11 @@ func main() {
 		}
 	}
 }
+
+func seedBookingCode() string {
+	code, err := ticketDomain.NewBookingCode()
+	if err != nil {
+		log.Fatalf("Failed to generate booking code: %v", err)
+	}
+	return code
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const claimsKey = "claims"
+
+// Claims mirrors the payload signed by UserService.Login.
+type Claims struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// JWTAuth rejects requests without a valid HS256 bearer token and stores the
+// token claims on the context for GetClaims / GetUserID.
+func JWTAuth(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tokenString, ok := bearerToken(c)
+		if !ok {
+			return c.Status(fiber.StatusUnauthorized).SendString("Missing or malformed token")
+		}
+
+		claims, err := parseToken(tokenString, secret)
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).SendString("Invalid or expired token")
+		}
+
+		c.Locals(claimsKey, claims)
+		return c.Next()
+	}
+}
+
+// OptionalJWTAuth behaves like JWTAuth when a valid token is sent but lets
+// anonymous requests through, for endpoints that only personalize their output.
+func OptionalJWTAuth(secret string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if tokenString, ok := bearerToken(c); ok {
+			if claims, err := parseToken(tokenString, secret); err == nil {
+				c.Locals(claimsKey, claims)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// GetClaims returns the claims stored by JWTAuth, or nil for anonymous requests.
+func GetClaims(c *fiber.Ctx) *Claims {
+	claims, _ := c.Locals(claimsKey).(*Claims)
+	return claims
+}
+
+// GetUserID returns the authenticated user's ID, or 0 for anonymous requests.
+func GetUserID(c *fiber.Ctx) int64 {
+	if claims := GetClaims(c); claims != nil {
+		return claims.UserID
+	}
+	return 0
+}
+
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	return token, ok && token != ""
+}
+
+func parseToken(tokenString, secret string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"strconv"
+
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CinemaAccessChecker reports whether a staff member is assigned to a cinema.
+type CinemaAccessChecker interface {
+	IsStaffAssigned(userID, cinemaID int64) (bool, error)
+}
+
+// GetRole returns the caller's role. Tokens issued before roles existed carry
+// no role and are treated as customers.
+func GetRole(c *fiber.Ctx) string {
+	if claims := GetClaims(c); claims != nil && claims.Role != "" {
+		return claims.Role
+	}
+	return userDomain.RoleCustomer
+}
+
+// RequireRoles only lets callers with one of the given roles through. It must
+// run after JWTAuth.
+func RequireRoles(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := GetRole(c)
+		for _, r := range roles {
+			if r == role {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).SendString("Insufficient permissions")
+	}
+}
+
+// RequireCinemaAccess guards routes scoped to the cinema in the given path
+// param. Chain and super admins may access every cinema, cinema staff only the
+// ones they are assigned to, and everyone else none. It must run after JWTAuth.
+func RequireCinemaAccess(param string, checker CinemaAccessChecker) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch GetRole(c) {
+		case userDomain.RoleSuperAdmin, userDomain.RoleChainAdmin:
+			return c.Next()
+		case userDomain.RoleCinemaStaff:
+			cinemaID, err := strconv.ParseInt(c.Params(param), 10, 64)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).SendString("Invalid cinema ID")
+			}
+			assigned, err := checker.IsStaffAssigned(GetUserID(c), cinemaID)
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+			}
+			if assigned {
+				return c.Next()
+			}
+		}
+		return c.Status(fiber.StatusForbidden).SendString("Insufficient permissions")
+	}
+}
@@ -1,11 +1,124 @@
 package domain
 
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+	_ "time/tzdata" // Cinemas resolve their zones even on hosts without tzdata
+
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+var (
+	ErrCinemaNotFound   = errors.New("cinema not found")
+	ErrTheaterNotFound  = errors.New("theater not found")
+	ErrInvalidSeatMap   = errors.New("invalid seat map")
+	ErrSeatMapInUse     = errors.New("seat map change would remove seats booked for upcoming showtimes")
+	ErrScannerNotFound  = errors.New("scanner device not found")
+	ErrInvalidDeviceKey = errors.New("unknown or revoked scanner device key")
+	ErrInvalidTimeZone  = errors.New("invalid time zone")
+)
+
+// DefaultTimeZone is WIB, where cinemas without an explicit zone are.
+const DefaultTimeZone = "Asia/Jakarta"
+
 type Cinema struct {
 	ID        int64   `gorm:"primaryKey" json:"id"`
 	Name      string  `gorm:"not null;type:varchar(100)" json:"name"`
 	City      string  `gorm:"not null;type:varchar(50)" json:"city"`
 	Address   string  `gorm:"type:text" json:"address"`
 	BasePrice float64 `gorm:"not null;type:decimal(10,2);default:50000" json:"base_price"`
+	TimeZone  string  `gorm:"type:varchar(50);not null;default:'Asia/Jakarta'" json:"time_zone"` // IANA, e.g. "Asia/Makassar"
+	// Coordinates in degrees; nil until the cinema has been placed on the map
+	Latitude  *float64 `gorm:"type:double precision;index:idx_cinemas_location" json:"latitude"`
+	Longitude *float64 `gorm:"type:double precision;index:idx_cinemas_location" json:"longitude"`
+}
+
+// CityStats summarizes the cinemas of one city. The centroid is the average
+// position of the city's cinemas that have coordinates, nil if none do.
+type CityStats struct {
+	City        string
+	CinemaCount int
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// BoundingBox is a latitude/longitude rectangle, in degrees.
+type BoundingBox struct {
+	MinLat, MaxLat float64
+	MinLng, MaxLng float64
+}
+
+const earthRadiusKm = 6371.0
+
+// kmPerDegreeLat is the length of one degree of latitude, and of longitude at
+// the equator.
+const kmPerDegreeLat = 111.195
+
+// BoundingBoxAround returns a box containing every point within radiusKm of
+// (lat, lng). Near the poles or across the antimeridian the longitude range
+// is widened to the whole globe rather than wrapped.
+func BoundingBoxAround(lat, lng, radiusKm float64) BoundingBox {
+	dLat := radiusKm / kmPerDegreeLat
+	box := BoundingBox{MinLat: math.Max(lat-dLat, -90), MaxLat: math.Min(lat+dLat, 90), MinLng: -180, MaxLng: 180}
+
+	cos := math.Cos(math.Max(math.Abs(lat)+dLat, 0) * math.Pi / 180)
+	if box.MaxLat < 90 && box.MinLat > -90 && cos > 0 {
+		dLng := radiusKm / (kmPerDegreeLat * cos)
+		if lng-dLng >= -180 && lng+dLng <= 180 {
+			box.MinLng, box.MaxLng = lng-dLng, lng+dLng
+		}
+	}
+	return box
+}
+
+// DistanceKm is the great-circle distance between two points, by the
+// haversine formula.
+func DistanceKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLng := (lng2 - lng1) * toRad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+var locations sync.Map // IANA name -> *time.Location
+
+// LoadTimeZone resolves an IANA zone name such as "Asia/Makassar". Only named
+// zones are accepted, not "Local" or bare offsets.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimeZone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// Location is the cinema's local time zone. Showtimes are stored in UTC and
+// shown, and grouped into days, on this clock.
+func (c Cinema) Location() *time.Location {
+	loc, err := LoadTimeZone(c.TimeZone)
+	if err != nil {
+		loc, _ = LoadTimeZone(DefaultTimeZone)
+	}
+	return loc
+}
+
+// LocalDay returns the UTC bounds [start, end) of the cinema's calendar day
+// that contains t.
+func (c Cinema) LocalDay(t time.Time) (time.Time, time.Time) {
+	local := t.In(c.Location())
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	return start.UTC(), start.AddDate(0, 0, 1).UTC()
 }
 
 type Theater struct {
@@ -16,10 +129,110 @@ type Theater struct {
 	Type     string `gorm:"type:varchar(20)" json:"type"`          // Regular, IMAX, Premiere
 }
 
+// Seat types a theater seat map can use.
+const (
+	SeatTypeStandard   = "standard"
+	SeatTypePremium    = "premium"
+	SeatTypeCouple     = "couple" // Sweetbox, sold per seat but placed in pairs
+	SeatTypeWheelchair = "wheelchair"
+	SeatTypeCompanion  = "companion" // Next to a wheelchair space
+)
+
+func IsValidSeatType(seatType string) bool {
+	switch seatType {
+	case SeatTypeStandard, SeatTypePremium, SeatTypeCouple, SeatTypeWheelchair, SeatTypeCompanion:
+		return true
+	}
+	return false
+}
+
+// TheaterSeat is one seat in a theater's seat map. RowIndex and ColumnIndex
+// place it on a grid counted from the screen and the left wall; gaps and
+// aisles are simply grid cells without a seat.
+type TheaterSeat struct {
+	ID          int64  `gorm:"primaryKey" json:"id"`
+	TheaterID   int64  `gorm:"not null;uniqueIndex:idx_theater_seats_seat" json:"theater_id"`
+	Row         string `gorm:"type:varchar(5);not null;uniqueIndex:idx_theater_seats_seat" json:"row"` // e.g. "G"
+	Number      int    `gorm:"not null;uniqueIndex:idx_theater_seats_seat" json:"number"`              // e.g. 14
+	RowIndex    int    `gorm:"not null" json:"row_index"`
+	ColumnIndex int    `gorm:"not null" json:"column_index"`
+	Type        string `gorm:"type:varchar(20);not null;default:'standard'" json:"type"`
+	Blocked     bool   `gorm:"not null;default:false" json:"blocked"` // Broken or otherwise not for sale
+}
+
+// DefaultSeatMap is the classic 12x8 layout (rows A-L, J-L premium) used for
+// theaters that have no seat map uploaded yet.
+func DefaultSeatMap(theaterID int64) []TheaterSeat {
+	rows := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
+	seats := make([]TheaterSeat, 0, len(rows)*8)
+	for i, r := range rows {
+		seatType := SeatTypeStandard
+		if i >= 9 { // J, K, L
+			seatType = SeatTypePremium
+		}
+		for n := 1; n <= 8; n++ {
+			seats = append(seats, TheaterSeat{
+				TheaterID:   theaterID,
+				Row:         r,
+				Number:      n,
+				RowIndex:    i,
+				ColumnIndex: n - 1,
+				Type:        seatType,
+			})
+		}
+	}
+	return seats
+}
+
+// ScannerDevice is a gate scanner registered to one cinema. It authenticates
+// with a device key, of which only the SHA-256 hash is stored.
+type ScannerDevice struct {
+	ID        int64      `gorm:"primaryKey" json:"id"`
+	CinemaID  int64      `gorm:"not null;index" json:"cinema_id"`
+	Name      string     `gorm:"type:varchar(100);not null" json:"name"` // e.g. "Gate 2 handheld"
+	KeyHash   string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CinemaStaff assigns a cinema_staff user to a cinema they may manage.
+type CinemaStaff struct {
+	UserID    int64     `gorm:"primaryKey" json:"user_id"`
+	CinemaID  int64     `gorm:"primaryKey" json:"cinema_id"`
+	Cinema    Cinema    `gorm:"foreignKey:CinemaID" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 type CinemaRepository interface {
-	GetAllCities() ([]string, error)
+	GetAllCities() ([]CityStats, error)
+	// GetCinemasByCity matches city case-insensitively; an empty city
+	// returns every cinema.
 	GetCinemasByCity(city string) ([]Cinema, error)
+	// GetCinemasPageByCity is GetCinemasByCity paged after the encoded
+	// cursor, empty for the first page.
+	GetCinemasPageByCity(city, cursor string, limit int) ([]Cinema, pagination.Meta, error)
+	// GetCinemasInBox returns the cinemas with coordinates inside box.
+	GetCinemasInBox(box BoundingBox) ([]Cinema, error)
+	UpdateLocation(id int64, lat, lng float64) error
 	GetByID(id int64) (*Cinema, error)
 	GetCinemaByShowtimeID(showtimeID int64) (*Cinema, error)
+	GetTheaterByID(id int64) (*Theater, error)
+	GetTheatersByCinema(cinemaID int64) ([]Theater, error)
+	CreateTheater(theater *Theater) error
+	GetTheaterSeats(theaterID int64) ([]TheaterSeat, error)
+	ReplaceTheaterSeats(theaterID int64, seats []TheaterSeat) error
+	UpdateTheaterSeats(seats []TheaterSeat) error
+
+	CreateScanner(scanner *ScannerDevice) error
+	GetScanners(cinemaID int64) ([]ScannerDevice, error)
+	GetScannerByID(id int64) (*ScannerDevice, error)
+	// GetActiveScannerByKeyHash ignores revoked devices.
+	GetActiveScannerByKeyHash(keyHash string) (*ScannerDevice, error)
+	RevokeScanner(cinemaID, id int64) error
 	Create(cinema *Cinema) error
+	UpdateTimeZone(id int64, timeZone string) error
+	GetStaff(cinemaID int64) ([]CinemaStaff, error)
+	AssignStaff(userID, cinemaID int64) error
+	UnassignStaff(userID, cinemaID int64) error
+	IsStaffAssigned(userID, cinemaID int64) (bool, error)
 }
@@ -1,21 +1,142 @@
 package dto
 
-import "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+import (
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	pricingDomain "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
 
 type CityResponse struct {
-	Cities []string `json:"cities"`
+	Cities []CityItem `json:"cities"`
+}
+
+type CityItem struct {
+	Name        string   `json:"name"`
+	CinemaCount int      `json:"cinema_count"`
+	Latitude    *float64 `json:"latitude"` // Centroid of the city's cinemas, null if none are placed
+	Longitude   *float64 `json:"longitude"`
 }
 
 type CinemaResponse struct {
-	ID      int64  `json:"id"`
-	Name    string `json:"name"`
-	City    string `json:"city"`
-	Address string `json:"address"`
+	ID        int64    `json:"id"`
+	Name      string   `json:"name"`
+	City      string   `json:"city"`
+	Address   string   `json:"address"`
+	TimeZone  string   `json:"time_zone"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+}
+
+// CinemaListResponse is the cinema list in cursor mode; without a cursor
+// the list is a bare array.
+type CinemaListResponse struct {
+	Cinemas []CinemaResponse `json:"cinemas"`
+	Cursor  pagination.Meta  `json:"cursor"`
+}
+
+type NearbyCinemaResponse struct {
+	CinemaResponse
+	DistanceKm float64 `json:"distance_km"` // Great-circle distance from the searched point
+}
+
+type LocationRequest struct {
+	Latitude  *float64 `json:"latitude" validate:"required,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required,gte=-180,lte=180"`
+}
+
+type TimeZoneRequest struct {
+	TimeZone string `json:"time_zone" validate:"required,max=50"` // IANA, e.g. "Asia/Jayapura"
+}
+
+type AssignStaffRequest struct {
+	UserID int64 `json:"user_id" validate:"required,gt=0"`
+}
+
+type StaffResponse struct {
+	UserID     int64  `json:"user_id"`
+	CinemaID   int64  `json:"cinema_id"`
+	AssignedAt string `json:"assigned_at"`
+}
+
+type RegisterScannerRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type ScannerResponse struct {
+	ID        int64      `json:"id"`
+	CinemaID  int64      `json:"cinema_id"`
+	Name      string     `json:"name"`
+	DeviceKey string     `json:"device_key,omitempty"` // Only returned on registration
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+type CreateTheaterRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Type string `json:"type" validate:"required,oneof=Regular IMAX Premiere"`
+}
+
+type TheaterResponse struct {
+	ID       int64  `json:"id"`
+	CinemaID int64  `json:"cinema_id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+}
+
+// SeatMapRequest replaces a theater's whole seat map. Rows are listed from
+// the screen backwards; a row without seats leaves an empty walkway.
+type SeatMapRequest struct {
+	Rows []SeatMapRow `json:"rows" validate:"required,min=1,dive"`
+}
+
+type SeatMapRow struct {
+	Label string           `json:"label" validate:"max=5"`
+	Seats []SeatMapRowSeat `json:"seats" validate:"dive"`
+}
+
+// SeatMapRowSeat places a seat at Column within its row; skipped columns
+// become aisles.
+type SeatMapRowSeat struct {
+	Number  int    `json:"number" validate:"required,gt=0"`
+	Column  int    `json:"column" validate:"gte=0"`
+	Type    string `json:"type" validate:"omitempty,oneof=standard premium couple wheelchair companion"`
+	Blocked bool   `json:"blocked"`
+}
+
+// UpdateSeatsRequest edits individual seats without touching the layout.
+type UpdateSeatsRequest struct {
+	Seats []SeatUpdate `json:"seats" validate:"required,min=1,dive"`
+}
+
+type SeatUpdate struct {
+	Seat    string  `json:"seat" validate:"required"` // e.g. "G14"
+	Type    *string `json:"type" validate:"omitempty,oneof=standard premium couple wheelchair companion"`
+	Blocked *bool   `json:"blocked"`
+}
+
+type SeatMapResponse struct {
+	TheaterID int64         `json:"theater_id"`
+	Rows      int           `json:"rows"`
+	Cols      int           `json:"cols"`
+	Seats     []SeatMapSeat `json:"seats"`
+}
+
+type SeatMapSeat struct {
+	Row     string `json:"row"`
+	Number  int    `json:"number"`
+	RowIdx  int    `json:"row_index"`
+	Column  int    `json:"column"`
+	Type    string `json:"type"`
+	Blocked bool   `json:"blocked"`
 }
 
 type SeatLayoutResponse struct {
 	Layout SeatLayout `json:"layout"`
 	Legend SeatLegend `json:"legend"`
+	// Pricing explains each seat type's price, keyed by seat type
+	Pricing map[string]pricingDomain.Quote `json:"pricing"`
 }
 
 type SeatLayout struct {
@@ -27,22 +148,73 @@ type SeatLayout struct {
 type Seat struct {
 	Row    string  `json:"row"`
 	Number int     `json:"number"`
-	Status string  `json:"status"` // available, occupied, selected
-	Type   string  `json:"type"`   // standard, premium
+	RowIdx int     `json:"row_index"` // Grid position; gaps are aisles and walkways
+	Column int     `json:"column"`
+	Status string  `json:"status"` // available, occupied, held, selected, blocked
+	Type   string  `json:"type"`   // standard, premium, couple, wheelchair, companion
 	Price  float64 `json:"price"`
 }
 
 type SeatLegend struct {
 	Available string `json:"available"`
 	Occupied  string `json:"occupied"`
-	Selected  string `json:"selected"`
+	Blocked   string `json:"blocked"`
+	Held      string `json:"held"`     // Held by another user during checkout
+	Selected  string `json:"selected"` // Held by the requesting user
 }
 
 func ToCinemaResponse(c domain.Cinema) CinemaResponse {
 	return CinemaResponse{
-		ID:      c.ID,
-		Name:    c.Name,
-		City:    c.City,
-		Address: c.Address,
+		ID:        c.ID,
+		Name:      c.Name,
+		City:      c.City,
+		Address:   c.Address,
+		TimeZone:  c.TimeZone,
+		Latitude:  c.Latitude,
+		Longitude: c.Longitude,
+	}
+}
+
+func ToStaffResponse(s domain.CinemaStaff) StaffResponse {
+	return StaffResponse{
+		UserID:     s.UserID,
+		CinemaID:   s.CinemaID,
+		AssignedAt: s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func ToTheaterResponse(t domain.Theater) TheaterResponse {
+	return TheaterResponse{
+		ID:       t.ID,
+		CinemaID: t.CinemaID,
+		Name:     t.Name,
+		Type:     t.Type,
+	}
+}
+
+func ToSeatMapResponse(theaterID int64, seats []domain.TheaterSeat) SeatMapResponse {
+	resp := SeatMapResponse{TheaterID: theaterID, Seats: make([]SeatMapSeat, len(seats))}
+	for i, s := range seats {
+		resp.Seats[i] = SeatMapSeat{
+			Row:     s.Row,
+			Number:  s.Number,
+			RowIdx:  s.RowIndex,
+			Column:  s.ColumnIndex,
+			Type:    s.Type,
+			Blocked: s.Blocked,
+		}
+		resp.Rows = max(resp.Rows, s.RowIndex+1)
+		resp.Cols = max(resp.Cols, s.ColumnIndex+1)
+	}
+	return resp
+}
+
+func ToScannerResponse(s domain.ScannerDevice) ScannerResponse {
+	return ScannerResponse{
+		ID:        s.ID,
+		CinemaID:  s.CinemaID,
+		Name:      s.Name,
+		CreatedAt: s.CreatedAt,
+		RevokedAt: s.RevokedAt,
 	}
 }
@@ -1,29 +1,63 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"strconv"
 
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/dto"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/service"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type CinemaHandler struct {
-	Service *service.CinemaService
+	Service   *service.CinemaService
+	Validator *validator.Validate
 }
 
-func NewCinemaHandler(service *service.CinemaService) *CinemaHandler {
-	return &CinemaHandler{Service: service}
+func NewCinemaHandler(service *service.CinemaService, v *validator.Validate) *CinemaHandler {
+	return &CinemaHandler{Service: service, Validator: v}
 }
 
-func (h *CinemaHandler) RegisterRoutes(app *fiber.App) {
+// RegisterRoutes mounts the cinema routes. optionalAuth identifies the viewer,
+// when logged in, so their own held seats show up as "selected".
+func (h *CinemaHandler) RegisterRoutes(app *fiber.App, auth, optionalAuth fiber.Handler) {
 	cinemas := app.Group("/cinemas")
 	locations := app.Group("/locations")
 
 	locations.Get("/", h.handleGetLocations)
 	cinemas.Get("/", h.handleGetCinemas)
+	cinemas.Get("/nearby", h.handleGetNearbyCinemas)
 
 	// Seat Selection
-	app.Get("/showtimes/:id/seats", h.handleGetSeats)
+	app.Get("/showtimes/:id/seats", optionalAuth, h.handleGetSeats)
+
+	// Staff management
+	admin := app.Group("/admin/cinemas/:id", auth)
+	admin.Put("/time-zone", middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin), h.handleSetTimeZone)
+	admin.Put("/location", middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin), h.handleSetLocation)
+	admin.Get("/staff", middleware.RequireCinemaAccess("id", h.Service), h.handleGetStaff)
+	admin.Post("/staff", middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin), h.handleAssignStaff)
+	admin.Delete("/staff/:userId", middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin), h.handleUnassignStaff)
+
+	// Theaters and seat maps, manageable by the cinema's own staff
+	admin.Get("/theaters", middleware.RequireCinemaAccess("id", h.Service), h.handleGetTheaters)
+	admin.Post("/theaters", middleware.RequireCinemaAccess("id", h.Service), h.handleCreateTheater)
+	admin.Get("/theaters/:theaterId/seat-map", middleware.RequireCinemaAccess("id", h.Service), h.handleGetSeatMap)
+	admin.Put("/theaters/:theaterId/seat-map", middleware.RequireCinemaAccess("id", h.Service), h.handleReplaceSeatMap)
+	admin.Patch("/theaters/:theaterId/seat-map/seats", middleware.RequireCinemaAccess("id", h.Service), h.handleUpdateSeats)
+
+	// Gate scanner devices
+	admin.Get("/scanners", middleware.RequireCinemaAccess("id", h.Service), h.handleGetScanners)
+	admin.Post("/scanners", middleware.RequireCinemaAccess("id", h.Service), h.handleRegisterScanner)
+	admin.Delete("/scanners/:scannerId", middleware.RequireCinemaAccess("id", h.Service), h.handleRevokeScanner)
 }
 
 func (h *CinemaHandler) handleGetLocations(c *fiber.Ctx) error {
@@ -35,13 +69,114 @@ func (h *CinemaHandler) handleGetLocations(c *fiber.Ctx) error {
 }
 
 func (h *CinemaHandler) handleGetCinemas(c *fiber.Ctx) error {
-	city := c.Query("city")
-	if city == "" {
-		return c.Status(fiber.StatusBadRequest).SendString("City parameter is required")
+	if c.Context().QueryArgs().Has("cursor") {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		resp, err := h.Service.GetCinemasByCursor(c.Query("city"), c.Query("cursor"), limit)
+		if err != nil {
+			if errors.Is(err, pagination.ErrInvalidCursor) {
+				return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+			}
+			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		}
+		return c.JSON(resp)
+	}
+
+	resp, err := h.Service.GetCinemas(c.Query("city"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+// Nearby search radius bounds, in kilometers.
+const (
+	defaultNearbyRadiusKm = 10
+	maxNearbyRadiusKm     = 100
+)
+
+func (h *CinemaHandler) handleGetNearbyCinemas(c *fiber.Ctx) error {
+	lat, err := parseFinite(c.Query("lat"))
+	if err != nil || lat < -90 || lat > 90 {
+		return c.Status(fiber.StatusBadRequest).SendString("lat must be between -90 and 90")
+	}
+	lng, err := parseFinite(c.Query("lng"))
+	if err != nil || lng < -180 || lng > 180 {
+		return c.Status(fiber.StatusBadRequest).SendString("lng must be between -180 and 180")
+	}
+	radius := float64(defaultNearbyRadiusKm)
+	if raw := c.Query("radius_km"); raw != "" {
+		radius, err = parseFinite(raw)
+		if err != nil || radius <= 0 || radius > maxNearbyRadiusKm {
+			return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("radius_km must be between 0 and %d", maxNearbyRadiusKm))
+		}
+	}
+
+	resp, err := h.Service.GetNearbyCinemas(lat, lng, radius)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+// parseFinite parses a float query value. ParseFloat accepts "NaN" and "Inf",
+// which slip through range checks since every comparison with NaN is false.
+func parseFinite(raw string) (float64, error) {
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, errors.New("not a finite number")
+	}
+	return v, nil
+}
+
+func (h *CinemaHandler) handleSetLocation(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.LocationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.SetLocation(id, *req.Latitude, *req.Longitude)
+	if err != nil {
+		if errors.Is(err, domain.ErrCinemaNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleSetTimeZone(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.TimeZoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
 	}
 
-	resp, err := h.Service.GetCinemas(city)
+	resp, err := h.Service.SetTimeZone(id, req.TimeZone)
 	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeZone):
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		case errors.Is(err, domain.ErrCinemaNotFound):
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
 	return c.JSON(resp)
@@ -54,9 +189,230 @@ func (h *CinemaHandler) handleGetSeats(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
 	}
 
-	resp, err := h.Service.GetSeatLayout(id)
+	resp, err := h.Service.GetSeatLayout(id, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, movieDomain.ErrShowtimeNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleGetStaff(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetStaff(cinemaID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleAssignStaff(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.AssignStaffRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	if err := h.Service.AssignStaff(req.UserID, cinemaID); err != nil {
+		if errors.Is(err, domain.ErrCinemaNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CinemaHandler) handleUnassignStaff(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	userID, err := strconv.ParseInt(c.Params("userId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid user ID")
+	}
+
+	if err := h.Service.UnassignStaff(userID, cinemaID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CinemaHandler) handleGetTheaters(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetTheaters(cinemaID)
+	if err != nil {
+		return c.Status(seatMapErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleCreateTheater(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.CreateTheaterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateTheater(cinemaID, req)
+	if err != nil {
+		return c.Status(seatMapErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *CinemaHandler) handleGetSeatMap(c *fiber.Ctx) error {
+	cinemaID, theaterID, err := theaterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetSeatMap(cinemaID, theaterID)
+	if err != nil {
+		return c.Status(seatMapErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleReplaceSeatMap(c *fiber.Ctx) error {
+	cinemaID, theaterID, err := theaterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.SeatMapRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.ReplaceSeatMap(cinemaID, theaterID, req)
+	if err != nil {
+		return c.Status(seatMapErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *CinemaHandler) handleUpdateSeats(c *fiber.Ctx) error {
+	cinemaID, theaterID, err := theaterParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.UpdateSeatsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.UpdateSeats(cinemaID, theaterID, req)
+	if err != nil {
+		return c.Status(seatMapErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func theaterParams(c *fiber.Ctx) (cinemaID, theaterID int64, err error) {
+	if cinemaID, err = strconv.ParseInt(c.Params("id"), 10, 64); err != nil {
+		return 0, 0, err
+	}
+	theaterID, err = strconv.ParseInt(c.Params("theaterId"), 10, 64)
+	return cinemaID, theaterID, err
+}
+
+func seatMapErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCinemaNotFound), errors.Is(err, domain.ErrTheaterNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSeatMap):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrSeatMapInUse):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *CinemaHandler) handleGetScanners(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetScanners(cinemaID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
 	return c.JSON(resp)
 }
+
+func (h *CinemaHandler) handleRegisterScanner(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.RegisterScannerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.RegisterScanner(cinemaID, req.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrCinemaNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *CinemaHandler) handleRevokeScanner(c *fiber.Ctx) error {
+	cinemaID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	scannerID, err := strconv.ParseInt(c.Params("scannerId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid scanner ID")
+	}
+
+	if err := h.Service.RevokeScanner(cinemaID, scannerID); err != nil {
+		if errors.Is(err, domain.ErrScannerNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -1,8 +1,14 @@
 package repository
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresCinemaRepository struct {
@@ -13,30 +19,154 @@ func NewPostgresCinemaRepository(db *gorm.DB) *PostgresCinemaRepository {
 	return &PostgresCinemaRepository{DB: db}
 }
 
-func (r *PostgresCinemaRepository) GetAllCities() ([]string, error) {
-	var cities []string
-	if err := r.DB.Model(&domain.Cinema{}).Distinct("city").Pluck("city", &cities).Error; err != nil {
+func (r *PostgresCinemaRepository) GetAllCities() ([]domain.CityStats, error) {
+	var cities []domain.CityStats
+	// Cities are matched case-insensitively elsewhere, so "Jakarta" and
+	// "jakarta" are one city here too. AVG skips NULLs, so cinemas without
+	// coordinates don't drag the centroid
+	if err := r.DB.Model(&domain.Cinema{}).
+		Select("MIN(city) AS city, COUNT(*) AS cinema_count, AVG(latitude) AS latitude, AVG(longitude) AS longitude").
+		Group("LOWER(city)").Order("LOWER(city)").Scan(&cities).Error; err != nil {
 		return nil, err
 	}
 	return cities, nil
 }
 
+// cinemaOrder lists cinemas by name.
+var cinemaOrder = pagination.Order{Name: "name", Key: "cinemas.name", KeyType: "text", ID: "cinemas.id"}
+
+func (r *PostgresCinemaRepository) inCity(city string) *gorm.DB {
+	query := r.DB.Model(&domain.Cinema{})
+	if city = strings.TrimSpace(city); city != "" {
+		query = query.Where("LOWER(cinemas.city) = LOWER(?)", city)
+	}
+	return query
+}
+
 func (r *PostgresCinemaRepository) GetCinemasByCity(city string) ([]domain.Cinema, error) {
 	var cinemas []domain.Cinema
-	if err := r.DB.Where("city = ?", city).Find(&cinemas).Error; err != nil {
+	if err := r.inCity(city).Order(cinemaOrder.SQL()).Find(&cinemas).Error; err != nil {
 		return nil, err
 	}
 	return cinemas, nil
 }
 
+func (r *PostgresCinemaRepository) GetCinemasPageByCity(city, cursor string, limit int) ([]domain.Cinema, pagination.Meta, error) {
+	after, err := pagination.Decode(cursor, cinemaOrder)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	ids, meta, err := pagination.Keyset(r.inCity(city), cinemaOrder, after, limit)
+	if err != nil || len(ids) == 0 {
+		return nil, meta, err
+	}
+
+	var cinemas []domain.Cinema
+	if err := r.DB.Find(&cinemas, ids).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return pagination.Arrange(cinemas, ids, func(c domain.Cinema) int64 { return c.ID }), meta, nil
+}
+
+func (r *PostgresCinemaRepository) GetCinemasInBox(box domain.BoundingBox) ([]domain.Cinema, error) {
+	var cinemas []domain.Cinema
+	if err := r.DB.Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?",
+		box.MinLat, box.MaxLat, box.MinLng, box.MaxLng).Find(&cinemas).Error; err != nil {
+		return nil, err
+	}
+	return cinemas, nil
+}
+
+func (r *PostgresCinemaRepository) UpdateLocation(id int64, lat, lng float64) error {
+	result := r.DB.Model(&domain.Cinema{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"latitude": lat, "longitude": lng})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCinemaNotFound
+	}
+	return nil
+}
+
 func (r *PostgresCinemaRepository) GetByID(id int64) (*domain.Cinema, error) {
 	var cinema domain.Cinema
 	if err := r.DB.First(&cinema, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrCinemaNotFound
+		}
 		return nil, err
 	}
 	return &cinema, nil
 }
 
+func (r *PostgresCinemaRepository) UpdateTimeZone(id int64, timeZone string) error {
+	result := r.DB.Model(&domain.Cinema{}).Where("id = ?", id).Update("time_zone", timeZone)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrCinemaNotFound
+	}
+	return nil
+}
+
+func (r *PostgresCinemaRepository) GetTheaterByID(id int64) (*domain.Theater, error) {
+	var theater domain.Theater
+	if err := r.DB.Preload("Cinema").First(&theater, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTheaterNotFound
+		}
+		return nil, err
+	}
+	return &theater, nil
+}
+
+func (r *PostgresCinemaRepository) GetTheatersByCinema(cinemaID int64) ([]domain.Theater, error) {
+	var theaters []domain.Theater
+	if err := r.DB.Where("cinema_id = ?", cinemaID).Order("id").Find(&theaters).Error; err != nil {
+		return nil, err
+	}
+	return theaters, nil
+}
+
+func (r *PostgresCinemaRepository) CreateTheater(theater *domain.Theater) error {
+	return r.DB.Create(theater).Error
+}
+
+func (r *PostgresCinemaRepository) GetTheaterSeats(theaterID int64) ([]domain.TheaterSeat, error) {
+	var seats []domain.TheaterSeat
+	if err := r.DB.Where("theater_id = ?", theaterID).Order("row_index, column_index").Find(&seats).Error; err != nil {
+		return nil, err
+	}
+	return seats, nil
+}
+
+func (r *PostgresCinemaRepository) ReplaceTheaterSeats(theaterID int64, seats []domain.TheaterSeat) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("theater_id = ?", theaterID).Delete(&domain.TheaterSeat{}).Error; err != nil {
+			return err
+		}
+		if len(seats) == 0 {
+			return nil
+		}
+		return tx.Create(&seats).Error
+	})
+}
+
+func (r *PostgresCinemaRepository) UpdateTheaterSeats(seats []domain.TheaterSeat) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		for _, seat := range seats {
+			if err := tx.Model(&domain.TheaterSeat{}).
+				Where("id = ?", seat.ID).
+				Updates(map[string]interface{}{"type": seat.Type, "blocked": seat.Blocked}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *PostgresCinemaRepository) Create(cinema *domain.Cinema) error {
 	return r.DB.Create(cinema).Error
 }
@@ -59,3 +189,78 @@ func (r *PostgresCinemaRepository) GetCinemaByShowtimeID(showtimeID int64) (*dom
 	}
 	return &cinema, nil
 }
+
+func (r *PostgresCinemaRepository) GetStaff(cinemaID int64) ([]domain.CinemaStaff, error) {
+	var staff []domain.CinemaStaff
+	if err := r.DB.Where("cinema_id = ?", cinemaID).Order("created_at").Find(&staff).Error; err != nil {
+		return nil, err
+	}
+	return staff, nil
+}
+
+func (r *PostgresCinemaRepository) AssignStaff(userID, cinemaID int64) error {
+	// Assigning twice is a no-op
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.CinemaStaff{UserID: userID, CinemaID: cinemaID}).Error
+}
+
+func (r *PostgresCinemaRepository) UnassignStaff(userID, cinemaID int64) error {
+	return r.DB.Where("user_id = ? AND cinema_id = ?", userID, cinemaID).Delete(&domain.CinemaStaff{}).Error
+}
+
+func (r *PostgresCinemaRepository) IsStaffAssigned(userID, cinemaID int64) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&domain.CinemaStaff{}).
+		Where("user_id = ? AND cinema_id = ?", userID, cinemaID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PostgresCinemaRepository) CreateScanner(scanner *domain.ScannerDevice) error {
+	return r.DB.Create(scanner).Error
+}
+
+func (r *PostgresCinemaRepository) GetScanners(cinemaID int64) ([]domain.ScannerDevice, error) {
+	var scanners []domain.ScannerDevice
+	if err := r.DB.Where("cinema_id = ?", cinemaID).Order("id").Find(&scanners).Error; err != nil {
+		return nil, err
+	}
+	return scanners, nil
+}
+
+func (r *PostgresCinemaRepository) GetScannerByID(id int64) (*domain.ScannerDevice, error) {
+	var scanner domain.ScannerDevice
+	if err := r.DB.First(&scanner, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrScannerNotFound
+		}
+		return nil, err
+	}
+	return &scanner, nil
+}
+
+func (r *PostgresCinemaRepository) GetActiveScannerByKeyHash(keyHash string) (*domain.ScannerDevice, error) {
+	var scanner domain.ScannerDevice
+	if err := r.DB.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&scanner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidDeviceKey
+		}
+		return nil, err
+	}
+	return &scanner, nil
+}
+
+func (r *PostgresCinemaRepository) RevokeScanner(cinemaID, id int64) error {
+	result := r.DB.Model(&domain.ScannerDevice{}).
+		Where("id = ? AND cinema_id = ? AND revoked_at IS NULL", id, cinemaID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrScannerNotFound
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/dto"
+)
+
+// RegisterScanner adds a gate scanner to the cinema. The device key is only
+// returned here; afterwards it cannot be recovered, only revoked.
+func (s *CinemaService) RegisterScanner(cinemaID int64, name string) (*dto.ScannerResponse, error) {
+	if _, err := s.Repo.GetByID(cinemaID); err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate device key: %w", err)
+	}
+	key := "scn_" + base64.RawURLEncoding.EncodeToString(b)
+
+	scanner := &domain.ScannerDevice{CinemaID: cinemaID, Name: name, KeyHash: hashDeviceKey(key)}
+	if err := s.Repo.CreateScanner(scanner); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToScannerResponse(*scanner)
+	resp.DeviceKey = key
+	return &resp, nil
+}
+
+func (s *CinemaService) GetScanners(cinemaID int64) ([]dto.ScannerResponse, error) {
+	scanners, err := s.Repo.GetScanners(cinemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.ScannerResponse, len(scanners))
+	for i, sc := range scanners {
+		resp[i] = dto.ToScannerResponse(sc)
+	}
+	return resp, nil
+}
+
+func (s *CinemaService) RevokeScanner(cinemaID, id int64) error {
+	return s.Repo.RevokeScanner(cinemaID, id)
+}
+
+// AuthenticateScanner resolves a device key to its scanner, and with it the
+// cinema the scanner stands in.
+func (s *CinemaService) AuthenticateScanner(key string) (*domain.ScannerDevice, error) {
+	if key == "" {
+		return nil, domain.ErrInvalidDeviceKey
+	}
+	return s.Repo.GetActiveScannerByKeyHash(hashDeviceKey(key))
+}
+
+func (s *CinemaService) GetScanner(id int64) (*domain.ScannerDevice, error) {
+	return s.Repo.GetScannerByID(id)
+}
+
+func (s *CinemaService) GetCinema(id int64) (*domain.Cinema, error) {
+	return s.Repo.GetByID(id)
+}
+
+func hashDeviceKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/dto"
+	ticketDomain "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+)
+
+func (s *CinemaService) GetTheaters(cinemaID int64) ([]dto.TheaterResponse, error) {
+	if _, err := s.Repo.GetByID(cinemaID); err != nil {
+		return nil, err
+	}
+	theaters, err := s.Repo.GetTheatersByCinema(cinemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.TheaterResponse, len(theaters))
+	for i, t := range theaters {
+		resp[i] = dto.ToTheaterResponse(t)
+	}
+	return resp, nil
+}
+
+// CreateTheater adds a theater to the cinema with the default seat map, so it
+// can be scheduled right away and customized later.
+func (s *CinemaService) CreateTheater(cinemaID int64, req dto.CreateTheaterRequest) (*dto.TheaterResponse, error) {
+	if _, err := s.Repo.GetByID(cinemaID); err != nil {
+		return nil, err
+	}
+
+	theater := &domain.Theater{CinemaID: cinemaID, Name: req.Name, Type: req.Type}
+	if err := s.Repo.CreateTheater(theater); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.ReplaceTheaterSeats(theater.ID, domain.DefaultSeatMap(theater.ID)); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToTheaterResponse(*theater)
+	return &resp, nil
+}
+
+func (s *CinemaService) GetSeatMap(cinemaID, theaterID int64) (*dto.SeatMapResponse, error) {
+	if err := s.checkTheater(cinemaID, theaterID); err != nil {
+		return nil, err
+	}
+	seats, err := s.Repo.GetTheaterSeats(theaterID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToSeatMapResponse(theaterID, seats)
+	return &resp, nil
+}
+
+// ReplaceSeatMap uploads a new seat map for the theater. It is refused when
+// it would drop a seat that is already booked for an upcoming showtime.
+func (s *CinemaService) ReplaceSeatMap(cinemaID, theaterID int64, req dto.SeatMapRequest) (*dto.SeatMapResponse, error) {
+	if err := s.checkTheater(cinemaID, theaterID); err != nil {
+		return nil, err
+	}
+
+	var seats []domain.TheaterSeat
+	present := make(map[string]bool)
+	for rowIdx, row := range req.Rows {
+		if len(row.Seats) == 0 {
+			continue // walkway
+		}
+		label := strings.ToUpper(strings.TrimSpace(row.Label))
+		if !isRowLabel(label) {
+			return nil, fmt.Errorf("%w: row label %q must be letters only", domain.ErrInvalidSeatMap, row.Label)
+		}
+
+		columns := make(map[int]bool)
+		for _, seat := range row.Seats {
+			key := fmt.Sprintf("%s%d", label, seat.Number)
+			if present[key] {
+				return nil, fmt.Errorf("%w: duplicate seat %s", domain.ErrInvalidSeatMap, key)
+			}
+			if columns[seat.Column] {
+				return nil, fmt.Errorf("%w: seats overlap at row %s column %d", domain.ErrInvalidSeatMap, label, seat.Column)
+			}
+			present[key] = true
+			columns[seat.Column] = true
+
+			seatType := seat.Type
+			if seatType == "" {
+				seatType = domain.SeatTypeStandard
+			}
+			seats = append(seats, domain.TheaterSeat{
+				TheaterID:   theaterID,
+				Row:         label,
+				Number:      seat.Number,
+				RowIndex:    rowIdx,
+				ColumnIndex: seat.Column,
+				Type:        seatType,
+				Blocked:     seat.Blocked,
+			})
+		}
+	}
+	if len(seats) == 0 {
+		return nil, fmt.Errorf("%w: no seats", domain.ErrInvalidSeatMap)
+	}
+
+	booked, err := s.TicketRepo.GetUpcomingBookedSeats(theaterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, seat := range booked {
+		if !present[seat] {
+			return nil, fmt.Errorf("%w: %s", domain.ErrSeatMapInUse, seat)
+		}
+	}
+
+	if err := s.Repo.ReplaceTheaterSeats(theaterID, seats); err != nil {
+		return nil, err
+	}
+	resp := dto.ToSeatMapResponse(theaterID, seats)
+	return &resp, nil
+}
+
+// UpdateSeats changes the type or blocked flag of individual seats, e.g. to
+// take a broken seat out of sale. Seats already sold stay sold.
+func (s *CinemaService) UpdateSeats(cinemaID, theaterID int64, req dto.UpdateSeatsRequest) (*dto.SeatMapResponse, error) {
+	if err := s.checkTheater(cinemaID, theaterID); err != nil {
+		return nil, err
+	}
+	seats, err := s.Repo.GetTheaterSeats(theaterID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(seats))
+	for i, seat := range seats {
+		index[fmt.Sprintf("%s%d", seat.Row, seat.Number)] = i
+	}
+
+	changed := make([]domain.TheaterSeat, 0, len(req.Seats))
+	for _, update := range req.Seats {
+		row, number, err := ticketDomain.ParseSeatLabel(update.Seat)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown seat %s", domain.ErrInvalidSeatMap, update.Seat)
+		}
+		i, ok := index[fmt.Sprintf("%s%d", row, number)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown seat %s", domain.ErrInvalidSeatMap, update.Seat)
+		}
+		if update.Type != nil {
+			seats[i].Type = *update.Type
+		}
+		if update.Blocked != nil {
+			seats[i].Blocked = *update.Blocked
+		}
+		changed = append(changed, seats[i])
+	}
+
+	if err := s.Repo.UpdateTheaterSeats(changed); err != nil {
+		return nil, err
+	}
+	resp := dto.ToSeatMapResponse(theaterID, seats)
+	return &resp, nil
+}
+
+// checkTheater makes sure the theater belongs to the cinema in the URL, which
+// is the cinema RequireCinemaAccess authorized.
+func (s *CinemaService) checkTheater(cinemaID, theaterID int64) error {
+	theater, err := s.Repo.GetTheaterByID(theaterID)
+	if err != nil {
+		return err
+	}
+	if theater.CinemaID != cinemaID {
+		return domain.ErrTheaterNotFound
+	}
+	return nil
+}
+
+func isRowLabel(label string) bool {
+	if label == "" {
+		return false
+	}
+	for _, r := range label {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
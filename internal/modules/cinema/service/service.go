@@ -2,20 +2,28 @@ package service
 
 import (
 	"fmt"
-	"strings"
+	"math"
+	"sort"
 
 	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/dto"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	pricingDomain "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	pricingService "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/service"
 	ticketDomain "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 )
 
 type CinemaService struct {
 	Repo       domain.CinemaRepository
 	TicketRepo ticketDomain.TicketRepository
+	HoldRepo   ticketDomain.SeatHoldRepository
+	MovieRepo  movieDomain.MovieRepository
+	Pricing    *pricingService.PricingService
 }
 
-func NewCinemaService(repo domain.CinemaRepository, ticketRepo ticketDomain.TicketRepository) *CinemaService {
-	return &CinemaService{Repo: repo, TicketRepo: ticketRepo}
+func NewCinemaService(repo domain.CinemaRepository, ticketRepo ticketDomain.TicketRepository, holdRepo ticketDomain.SeatHoldRepository, movieRepo movieDomain.MovieRepository, pricing *pricingService.PricingService) *CinemaService {
+	return &CinemaService{Repo: repo, TicketRepo: ticketRepo, HoldRepo: holdRepo, MovieRepo: movieRepo, Pricing: pricing}
 }
 
 func (s *CinemaService) GetLocations() (*dto.CityResponse, error) {
@@ -23,119 +31,221 @@ func (s *CinemaService) GetLocations() (*dto.CityResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &dto.CityResponse{Cities: cities}, nil
+
+	resp := &dto.CityResponse{Cities: make([]dto.CityItem, len(cities))}
+	for i, c := range cities {
+		resp.Cities[i] = dto.CityItem{Name: c.City, CinemaCount: c.CinemaCount, Latitude: c.Latitude, Longitude: c.Longitude}
+	}
+	return resp, nil
 }
 
+// GetCinemas lists the cinemas in city, or all cinemas when city is empty.
 func (s *CinemaService) GetCinemas(city string) ([]dto.CinemaResponse, error) {
 	cinemas, err := s.Repo.GetCinemasByCity(city)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp []dto.CinemaResponse
+	resp := []dto.CinemaResponse{}
 	for _, c := range cinemas {
 		resp = append(resp, dto.ToCinemaResponse(c))
 	}
 	return resp, nil
 }
 
-func (s *CinemaService) GetSeatLayout(showtimeID int64) (*dto.SeatLayoutResponse, error) {
-	// 1. Fetch Booked Seats
-	bookedSeatStrings, err := s.TicketRepo.GetBookedSeats(showtimeID)
+// GetCinemasByCursor is GetCinemas a page at a time, after the encoded cursor.
+func (s *CinemaService) GetCinemasByCursor(city, cursor string, limit int) (*dto.CinemaListResponse, error) {
+	cinemas, meta, err := s.Repo.GetCinemasPageByCity(city, cursor, pagination.Limit(limit))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch booked seats: %w", err)
+		return nil, err
 	}
 
-	// 1b. Fetch Cinema details via Showtime (we need to know which cinema to get base price)
-	// Currently we only have showtimeID. We need to fetch Showtime -> Cinema.
-	// We don't have ShowtimeRepo here. But we can fetch Cinema if we had CinemaID.
-	// Ideally TicketRepo or a new ShowtimeRepo method would give us this.
-	// For now, let's assume we can query Cinema directly if we knew the ID.
-	// But we don't.
-	// HACK: We should really inject ShowtimeRepo or similar.
-	// OR: We can just fetch the Cinema associated with the showtime using GORM if we had access to DB.
-	// s.Repo is CinemaRepository. It can get Cinema by ID. But we need CinemaID from ShowtimeID.
+	resp := &dto.CinemaListResponse{Cinemas: []dto.CinemaResponse{}, Cursor: meta}
+	for _, c := range cinemas {
+		resp.Cinemas = append(resp.Cinemas, dto.ToCinemaResponse(c))
+	}
+	return resp, nil
+}
 
-	// Let's rely on TicketRepo to give us the cinema ID? Or just fetch everything?
-	// To do this properly, we need to fetch the Showtime entity first.
-	// Since we don't have ShowtimeRepo injected, we can't easily do it without changing dependencies.
+// GetNearbyCinemas returns the cinemas within radiusKm of (lat, lng), nearest
+// first. A bounding box narrows the candidates in the database; the exact
+// great-circle distance is checked here.
+func (s *CinemaService) GetNearbyCinemas(lat, lng, radiusKm float64) ([]dto.NearbyCinemaResponse, error) {
+	candidates, err := s.Repo.GetCinemasInBox(domain.BoundingBoxAround(lat, lng, radiusKm))
+	if err != nil {
+		return nil, err
+	}
 
-	// However, the prompt says "Refactor...".
-	// I should probably inject ShowtimeRepo or similar?
-	// OR: I can modify TicketRepo to return the Cinema ID for the showtime? NO, that's weird.
+	resp := []dto.NearbyCinemaResponse{}
+	for _, c := range candidates {
+		distance := domain.DistanceKm(lat, lng, *c.Latitude, *c.Longitude)
+		if distance > radiusKm {
+			continue
+		}
+		resp = append(resp, dto.NearbyCinemaResponse{
+			CinemaResponse: dto.ToCinemaResponse(c),
+			DistanceKm:     math.Round(distance*100) / 100,
+		})
+	}
+	sort.SliceStable(resp, func(i, j int) bool { return resp[i].DistanceKm < resp[j].DistanceKm })
+	return resp, nil
+}
 
-	// Better approach:
-	// Use `DB` directly? No, abstraction.
+// SetLocation places the cinema on the map for nearby search.
+func (s *CinemaService) SetLocation(id int64, lat, lng float64) (*dto.CinemaResponse, error) {
+	if err := s.Repo.UpdateLocation(id, lat, lng); err != nil {
+		return nil, err
+	}
+	cinema, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToCinemaResponse(*cinema)
+	return &resp, nil
+}
 
-	// Let's check `CinemaRepository`. Maybe add `GetCinemaByShowtimeID`?
-	// `GetCinemasByCity`, `GetByID`.
+// SetTimeZone moves the cinema to another IANA time zone. Showtimes keep their
+// instant in time; only how they are shown changes.
+func (s *CinemaService) SetTimeZone(id int64, timeZone string) (*dto.CinemaResponse, error) {
+	if _, err := domain.LoadTimeZone(timeZone); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.UpdateTimeZone(id, timeZone); err != nil {
+		return nil, err
+	}
+	cinema, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToCinemaResponse(*cinema)
+	return &resp, nil
+}
 
-	// I'll add `GetCinemaByShowtimeID` to `CinemaRepository` interface? Easiest way.
-	// But CinemaRepo is for Cinema domain.
-	// A showtime belongs to a cinema. So `GetCinemaByShowtimeID` is valid in CinemaRepo?
-	// It crosses domains slightly but it's okay.
+func (s *CinemaService) GetStaff(cinemaID int64) ([]dto.StaffResponse, error) {
+	staff, err := s.Repo.GetStaff(cinemaID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Or, I can leave the price hardcoded for a sec while I fix the lints? No, user wants logic.
+	resp := make([]dto.StaffResponse, len(staff))
+	for i, st := range staff {
+		resp[i] = dto.ToStaffResponse(st)
+	}
+	return resp, nil
+}
 
-	// Let's assume for this specific task I can add `GetCinemaByShowtimeID(showtimeID int64)` to `CinemaRepository`.
-	cinema, err := s.Repo.GetCinemaByShowtimeID(showtimeID)
+func (s *CinemaService) AssignStaff(userID, cinemaID int64) error {
+	if _, err := s.Repo.GetByID(cinemaID); err != nil {
+		return err
+	}
+	return s.Repo.AssignStaff(userID, cinemaID)
+}
+
+func (s *CinemaService) UnassignStaff(userID, cinemaID int64) error {
+	return s.Repo.UnassignStaff(userID, cinemaID)
+}
+
+// IsStaffAssigned lets the service act as middleware.CinemaAccessChecker.
+func (s *CinemaService) IsStaffAssigned(userID, cinemaID int64) (bool, error) {
+	return s.Repo.IsStaffAssigned(userID, cinemaID)
+}
+
+// GetSeatLayout renders the seat map for a showtime. Seats held by viewerID
+// are reported as "selected", seats held by anyone else as "held". Pass 0 for
+// an anonymous viewer.
+func (s *CinemaService) GetSeatLayout(showtimeID, viewerID int64) (*dto.SeatLayoutResponse, error) {
+	// 1. Fetch Booked Seats
+	bookedSeats, err := s.TicketRepo.GetBookedSeats(showtimeID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch cinema for showtime: %w", err)
+		return nil, fmt.Errorf("failed to fetch booked seats: %w", err)
 	}
 
-	// flatten booked seats: "A1, A2" -> ["A1", "A2"]
-	bookedMap := make(map[string]bool)
-	for _, s := range bookedSeatStrings {
-		parts := strings.Split(s, ",")
-		for _, p := range parts {
-			bookedMap[strings.TrimSpace(p)] = true
-		}
+	holds, err := s.HoldRepo.GetActiveByShowtime(showtimeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch seat holds: %w", err)
+	}
+	holders := make(map[string]int64)
+	for _, h := range holds {
+		holders[h.Seat] = h.UserID
 	}
 
-	// 2. Generate Static Layout (Row A-L, Cols 1-8 for example)
-	// In real app, this should come from Theater layout in DB.
-	rows := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
-	cols := 8
-	var seats []dto.Seat
-
-	for i, r := range rows {
-		seatType := "standard"
-		price := cinema.BasePrice
-		// Rows A-D are standard? User image shows Rows A-L.
-		// Let's assume K, L are premium or maybe closest to screen?
-		// Actually typical cinema: Back is VIP/Premium.
-		// Let's make J, K, L premium.
-		if i >= 9 { // J, K, L
-			seatType = "premium"
-			price += 25000.0 // Premium surcharge
+	// The showtime tells us the cinema (base price), the theater (seat map,
+	// format) and the movie and start time the price rules depend on
+	showtime, err := s.MovieRepo.GetShowtimeByID(showtimeID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Theaters without an uploaded seat map fall back to the default layout
+	var seatMap []domain.TheaterSeat
+	theaterType := "Regular"
+	if showtime.Theater != nil {
+		theaterType = showtime.Theater.Type
+		seatMap, err = s.Repo.GetTheaterSeats(showtime.Theater.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch seat map: %w", err)
 		}
+	}
+	if len(seatMap) == 0 {
+		seatMap = domain.DefaultSeatMap(0)
+	}
 
-		for c := 1; c <= cols; c++ {
-			seatNum := fmt.Sprintf("%s%d", r, c)
-			status := "available"
-			if bookedMap[seatNum] {
-				status = "occupied"
-			}
+	seatTypes := make([]string, len(seatMap))
+	for i, ts := range seatMap {
+		seatTypes[i] = ts.Type
+	}
+	quotes, err := s.Pricing.QuoteSeatTypes(pricingDomain.QuoteInput{
+		BasePrice:   showtime.Cinema.BasePrice,
+		TheaterType: theaterType,
+		MovieID:     showtime.MovieID,
+		StartTime:   showtime.StartTime.In(showtime.Cinema.Location()),
+	}, seatTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	bookedMap := make(map[string]bool)
+	for _, seat := range bookedSeats {
+		bookedMap[seat] = true
+	}
 
-			seats = append(seats, dto.Seat{
-				Row:    r,
-				Number: c,
-				Status: status,
-				Type:   seatType,
-				Price:  price,
-			})
+	// 2. Render the seat map with live availability
+	layout := dto.SeatLayout{Seats: make([]dto.Seat, 0, len(seatMap))}
+	for _, ts := range seatMap {
+		seatNum := fmt.Sprintf("%s%d", ts.Row, ts.Number)
+		status := "available"
+		if bookedMap[seatNum] {
+			status = "occupied"
+		} else if ts.Blocked {
+			status = "blocked"
+		} else if holder, ok := holders[seatNum]; ok {
+			status = "held"
+			if viewerID != 0 && holder == viewerID {
+				status = "selected"
+			}
 		}
+
+		layout.Seats = append(layout.Seats, dto.Seat{
+			Row:    ts.Row,
+			Number: ts.Number,
+			RowIdx: ts.RowIndex,
+			Column: ts.ColumnIndex,
+			Status: status,
+			Type:   ts.Type,
+			Price:  quotes[ts.Type].Price,
+		})
+		layout.Rows = max(layout.Rows, ts.RowIndex+1)
+		layout.Cols = max(layout.Cols, ts.ColumnIndex+1)
 	}
 
 	return &dto.SeatLayoutResponse{
-		Layout: dto.SeatLayout{
-			Rows:  len(rows),
-			Cols:  cols,
-			Seats: seats,
-		},
+		Layout:  layout,
+		Pricing: quotes,
 		Legend: dto.SeatLegend{
 			Available: "Available",
 			Occupied:  "Occupied",
+			Blocked:   "Unavailable",
+			Held:      "Held",
 			Selected:  "Selected",
 		},
 	}, nil
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"time"
+)
+
+// Job run statuses.
+const (
+	RunRunning   = "running"
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// Job is a task the scheduler runs every Interval. Run returns how many
+// records it changed.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(now time.Time) (int64, error)
+}
+
+// JobRun records one execution of a job on one instance.
+type JobRun struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	Job        string     `gorm:"type:varchar(50);not null;index" json:"job"`
+	Instance   string     `gorm:"type:varchar(100);not null" json:"instance"` // Hostname of the replica that ran it
+	Status     string     `gorm:"type:varchar(20);not null" json:"status"`
+	Affected   int64      `gorm:"not null;default:0" json:"affected"`
+	Error      string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt  time.Time  `gorm:"not null;index" json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+type JobRunRepository interface {
+	// RunExclusive calls fn only if no other instance is running the job
+	// with the same lock key, and reports whether fn ran.
+	RunExclusive(lockKey int64, fn func() error) (bool, error)
+	Create(run *JobRun) error
+	Finish(run *JobRun) error
+	GetRuns(job string, limit int) ([]JobRun, error)
+}
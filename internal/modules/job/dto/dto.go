@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/job/domain"
+)
+
+type JobRunResponse struct {
+	ID         int64      `json:"id"`
+	Job        string     `json:"job"`
+	Instance   string     `json:"instance"`
+	Status     string     `json:"status"`
+	Affected   int64      `json:"affected"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+func ToJobRunResponse(r domain.JobRun) JobRunResponse {
+	return JobRunResponse{
+		ID:         r.ID,
+		Job:        r.Job,
+		Instance:   r.Instance,
+		Status:     r.Status,
+		Affected:   r.Affected,
+		Error:      r.Error,
+		StartedAt:  r.StartedAt,
+		FinishedAt: r.FinishedAt,
+	}
+}
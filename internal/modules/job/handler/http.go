@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/job/service"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/gofiber/fiber/v2"
+)
+
+type JobHandler struct {
+	Scheduler *service.Scheduler
+}
+
+func NewJobHandler(scheduler *service.Scheduler) *JobHandler {
+	return &JobHandler{Scheduler: scheduler}
+}
+
+func (h *JobHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	jobs := app.Group("/admin/jobs", auth, middleware.RequireRoles(userDomain.RoleSuperAdmin))
+	jobs.Get("/runs", h.handleGetRuns)
+}
+
+// handleGetRuns lists the most recent runs, optionally of one job (?job=).
+func (h *JobHandler) handleGetRuns(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	resp, err := h.Scheduler.GetRuns(c.Query("job"), limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
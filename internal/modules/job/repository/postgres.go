@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"github.com/geraldiaditya/ratix-backend/internal/modules/job/domain"
+	"gorm.io/gorm"
+)
+
+type PostgresJobRunRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresJobRunRepository(db *gorm.DB) *PostgresJobRunRepository {
+	return &PostgresJobRunRepository{DB: db}
+}
+
+// RunExclusive holds a transaction-scoped advisory lock while fn runs, so the
+// lock is released even if this instance dies mid-run.
+func (r *PostgresJobRunRepository) RunExclusive(lockKey int64, fn func() error) (bool, error) {
+	var ran bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var locked bool
+		if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", lockKey).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+		ran = true
+		return fn()
+	})
+	return ran, err
+}
+
+func (r *PostgresJobRunRepository) Create(run *domain.JobRun) error {
+	return r.DB.Create(run).Error
+}
+
+func (r *PostgresJobRunRepository) Finish(run *domain.JobRun) error {
+	return r.DB.Model(run).Updates(map[string]interface{}{
+		"status":      run.Status,
+		"affected":    run.Affected,
+		"error":       run.Error,
+		"finished_at": run.FinishedAt,
+	}).Error
+}
+
+func (r *PostgresJobRunRepository) GetRuns(job string, limit int) ([]domain.JobRun, error) {
+	var runs []domain.JobRun
+	query := r.DB.Order("started_at desc").Limit(limit)
+	if job != "" {
+		query = query.Where("job = ?", job)
+	}
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/job/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/job/dto"
+)
+
+// Scheduler runs registered jobs on their interval. Every replica runs a
+// Scheduler; an advisory lock per job makes sure only one of them executes a
+// given job at a time, and every execution is recorded as a JobRun.
+type Scheduler struct {
+	Repo     domain.JobRunRepository
+	Instance string
+	Jobs     []domain.Job
+}
+
+func NewScheduler(repo domain.JobRunRepository, instance string) *Scheduler {
+	return &Scheduler{Repo: repo, Instance: instance}
+}
+
+func (s *Scheduler) Register(job domain.Job) {
+	s.Jobs = append(s.Jobs, job)
+}
+
+// Start runs every job once its first interval elapses, then on every tick,
+// until ctx is cancelled.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.Jobs {
+		go func(job domain.Job) {
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case now := <-ticker.C:
+					if err := s.runOnce(job, now); err != nil {
+						log.Printf("Warning: Job %s failed: %v", job.Name, err)
+					}
+				}
+			}
+		}(job)
+	}
+}
+
+func (s *Scheduler) runOnce(job domain.Job, now time.Time) error {
+	_, err := s.Repo.RunExclusive(lockKey(job.Name), func() error {
+		run := &domain.JobRun{
+			Job:       job.Name,
+			Instance:  s.Instance,
+			Status:    domain.RunRunning,
+			StartedAt: now,
+		}
+		if err := s.Repo.Create(run); err != nil {
+			return err
+		}
+
+		affected, jobErr := job.Run(now)
+		finished := time.Now()
+		run.FinishedAt = &finished
+		run.Affected = affected
+		run.Status = domain.RunSucceeded
+		if jobErr != nil {
+			run.Status = domain.RunFailed
+			run.Error = jobErr.Error()
+		}
+		if err := s.Repo.Finish(run); err != nil {
+			return err
+		}
+		return jobErr
+	})
+	return err
+}
+
+// lockKey derives a stable advisory lock key from the job name, so replicas
+// agree on it without coordination.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("job:" + name))
+	return int64(h.Sum64())
+}
+
+func (s *Scheduler) GetRuns(job string, limit int) ([]dto.JobRunResponse, error) {
+	runs, err := s.Repo.GetRuns(job, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.JobRunResponse, len(runs))
+	for i, r := range runs {
+		resp[i] = dto.ToJobRunResponse(r)
+	}
+	return resp, nil
+}
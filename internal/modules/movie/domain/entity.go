@@ -1,23 +1,98 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+var (
+	ErrMovieNotFound      = errors.New("movie not found")
+	ErrShowtimeNotFound   = errors.New("showtime not found")
+	ErrGenreNotFound      = errors.New("genre not found")
+	ErrGenreExists        = errors.New("genre already exists")
+	ErrCastMemberNotFound = errors.New("cast member not found")
+	ErrInvalidCastOrder   = errors.New("cast order must list every cast member of the movie exactly once")
+	ErrMovieInUse         = errors.New("movie has future showtimes or active tickets")
+	ErrMovieHasTickets    = errors.New("movie has ticket history, archive it instead")
+	ErrShowtimeInPast     = errors.New("showtime must start in the future")
+	ErrInvalidSchedule    = errors.New("invalid schedule")
+	ErrInvalidDate        = errors.New("date must be YYYY-MM-DD")
+	ErrEmptySearchQuery   = errors.New("search query has no words")
+	ErrInvalidMovieFilter = errors.New("invalid movie filter")
+	ErrPosterNotFound     = errors.New("poster not found")
+	ErrInvalidPoster      = errors.New("poster must be a public http(s) URL of a JPEG or PNG image")
+)
+
+// Movie statuses. Archived movies are hidden from every public listing.
+const (
+	StatusNowShowing = "now_showing"
+	StatusComingSoon = "coming_soon"
+	StatusArchived   = "archived"
+)
+
+// Age certifications issued by Indonesia's film censorship board (LSF).
+const (
+	CertificationAllAges = "SU" // Semua Umur
+	Certification13Plus  = "13+"
+	Certification17Plus  = "17+"
+	Certification21Plus  = "21+"
 )
 
 type Movie struct {
-	ID          int64        `gorm:"primaryKey" json:"id"`
-	Title       string       `gorm:"not null;type:varchar(255)" json:"title"`
-	Description string       `gorm:"type:text" json:"description"`
-	Duration    int          `gorm:"not null" json:"duration"` // in minutes
-	Rating      float64      `gorm:"type:decimal(3,1)" json:"rating"`
-	PosterURL   string       `gorm:"type:varchar(255)" json:"poster_url"`
-	ReleaseDate time.Time    `gorm:"type:date" json:"release_date"`
-	Status      string       `gorm:"type:varchar(50);default:'now_showing'" json:"status"` // now_showing, coming_soon
-	Genres      []Genre      `gorm:"many2many:movie_genres;" json:"genres"`
-	Cast        []CastMember `gorm:"foreignKey:MovieID" json:"cast"`
-	Showtimes   []Showtime   `gorm:"foreignKey:MovieID" json:"showtimes"`
+	ID          int64  `gorm:"primaryKey" json:"id"`
+	Title       string `gorm:"not null;type:varchar(255)" json:"title"`
+	Description string `gorm:"type:text" json:"description"`
+	Duration    int    `gorm:"not null" json:"duration"` // in minutes
+	// Rating is the Bayesian average of published user reviews, kept up to
+	// date by the review module; it keeps its seeded value until the first vote
+	Rating      float64 `gorm:"type:decimal(3,1)" json:"rating"`
+	RatingCount int     `gorm:"not null;default:0" json:"rating_count"`
+	RatingSum   int     `gorm:"not null;default:0" json:"-"` // Sum of the counted 1-10 votes
+	// AgeCertification is empty for movies not yet classified
+	AgeCertification string       `gorm:"type:varchar(10)" json:"age_certification"`
+	PosterURL        string       `gorm:"type:varchar(255)" json:"poster_url"`
+	ReleaseDate      time.Time    `gorm:"type:date" json:"release_date"`
+	Status           string       `gorm:"type:varchar(50);default:'now_showing'" json:"status"` // now_showing, coming_soon, archived
+	Genres           []Genre      `gorm:"many2many:movie_genres;" json:"genres"`
+	Cast             []CastMember `gorm:"foreignKey:MovieID" json:"cast"`
+	Showtimes        []Showtime   `gorm:"foreignKey:MovieID" json:"showtimes"`
+	// SearchKeywords are the genre and cast names folded into search; the
+	// repository keeps them in sync
+	SearchKeywords string `gorm:"type:text;not null;default:''" json:"-"`
+}
+
+// maxSearchTerms bounds how many words of a query are searched for.
+const maxSearchTerms = 8
+
+// SearchTerms splits a search query into lowercase words of letters and
+// digits, dropping punctuation so the words are safe to use in a tsquery.
+func SearchTerms(q string) []string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	return words
+}
+
+// RuntimeLabel renders the duration as e.g. "2h 15m".
+func (m Movie) RuntimeLabel() string {
+	h, min := m.Duration/60, m.Duration%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%dm", min)
+	case min == 0:
+		return fmt.Sprintf("%dh", h)
+	default:
+		return fmt.Sprintf("%dh %dm", h, min)
+	}
 }
 
 type Genre struct {
@@ -25,6 +100,17 @@ type Genre struct {
 	Name string `gorm:"not null;unique;type:varchar(100)" json:"name"`
 }
 
+// MoviePoster is the image at a movie's PosterURL, downloaded in the
+// background so documents never fetch it on demand. It is out of date while
+// URL differs from the movie's PosterURL. ImageType is "JPG" or "PNG".
+type MoviePoster struct {
+	MovieID   int64     `gorm:"primaryKey" json:"movie_id"`
+	URL       string    `gorm:"type:varchar(255);not null" json:"url"`
+	Image     []byte    `gorm:"type:bytea;not null" json:"-"`
+	ImageType string    `gorm:"type:varchar(3);not null" json:"image_type"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
 type CastMember struct {
 	ID            int64  `gorm:"primaryKey" json:"id"`
 	MovieID       int64  `gorm:"not null" json:"movie_id"`
@@ -32,21 +118,133 @@ type CastMember struct {
 	Role          string `gorm:"not null;type:varchar(50)" json:"role"`   // Actor, Director, etc.
 	CharacterName string `gorm:"type:varchar(255)" json:"character_name"` // For actors
 	PhotoURL      string `gorm:"type:varchar(255)" json:"photo_url"`
+	Position      int    `gorm:"not null;default:0" json:"position"` // Billing order, lowest first
 }
 
 type Showtime struct {
-	ID        int64         `gorm:"primaryKey" json:"id"`
-	MovieID   int64         `gorm:"not null" json:"movie_id"`
-	CinemaID  int64         `gorm:"not null;default:1" json:"cinema_id"` // Default 1 for migration safety
-	Cinema    domain.Cinema `gorm:"foreignKey:CinemaID" json:"cinema"`
-	StartTime time.Time     `gorm:"not null" json:"start_time"`
+	ID        int64           `gorm:"primaryKey" json:"id"`
+	MovieID   int64           `gorm:"not null" json:"movie_id"`
+	Movie     *Movie          `gorm:"foreignKey:MovieID" json:"-"`
+	CinemaID  int64           `gorm:"not null;default:1" json:"cinema_id"` // Default 1 for migration safety
+	Cinema    domain.Cinema   `gorm:"foreignKey:CinemaID" json:"cinema"`
+	TheaterID *int64          `gorm:"index" json:"theater_id"` // Nullable for showtimes scheduled before theaters
+	Theater   *domain.Theater `gorm:"foreignKey:TheaterID" json:"theater,omitempty"`
+	StartTime time.Time       `gorm:"not null" json:"start_time"`
+}
+
+// ShowtimeFilter narrows GetUpcomingShowtimes; zero fields do not filter.
+// Showtimes that already started or belong to archived movies never match.
+type ShowtimeFilter struct {
+	MovieID  int64
+	CinemaID int64
+	City     string
+	From     time.Time // StartTime window, From inclusive, To exclusive
+	To       time.Time
+}
+
+// Sort keys accepted by MovieRepository.List.
+const (
+	SortRating      = "rating"
+	SortReleaseDate = "release_date"
+	SortTitle       = "title"
+	SortPopularity  = "popularity" // Tickets sold, cancelled ones excluded
+)
+
+// MovieFilter narrows MovieRepository.List; zero fields do not filter and
+// all set fields must match. Archived movies never match.
+type MovieFilter struct {
+	Status         string
+	Genres         []string // Genre names, any of them, case-insensitive
+	ReleasedFrom   *time.Time
+	ReleasedTo     *time.Time // Inclusive
+	MinRating      float64
+	MaxDuration    int      // Minutes
+	Certifications []string // Any of them
+	// City and PlayingOn keep movies with an upcoming showtime in a cinema of
+	// the city and/or on that date (YYYY-MM-DD) by the cinema's own clock
+	City      string
+	PlayingOn string
+
+	Sort       string // One of the Sort keys; empty lists in the order added
+	Descending bool
+}
+
+// ShowtimeConflict describes a requested start time that overlaps an existing
+// (or another requested) showtime in the same theater.
+type ShowtimeConflict struct {
+	StartTime            time.Time `json:"start_time"`
+	ConflictingID        int64     `json:"conflicting_showtime_id,omitempty"` // 0 when it clashes within the request
+	ConflictingStartTime time.Time `json:"conflicting_start_time"`
+}
+
+type ShowtimeConflictError struct {
+	Conflicts []ShowtimeConflict
+}
+
+func (e *ShowtimeConflictError) Error() string {
+	return fmt.Sprintf("%d showtime(s) overlap existing showtimes in the theater", len(e.Conflicts))
 }
 
 type MovieRepository interface {
 	GetAll() ([]Movie, error)
 	GetByID(id int64) (*Movie, error)
-	GetByStatus(status string, limit, offset int) ([]Movie, int64, error)
-	GetByGenre(genre string, limit, offset int) ([]Movie, int64, error)
+	// List returns a page of the movies matching filter, with their genres,
+	// and the total number of matches.
+	List(filter MovieFilter, limit, offset int) ([]Movie, int64, error)
+	// ListByCursor returns the page of movies matching filter after the
+	// encoded cursor, empty for the first page. It fails with
+	// pagination.ErrInvalidCursor for cursors of another sort.
+	ListByCursor(filter MovieFilter, cursor string, limit int) ([]Movie, pagination.Meta, error)
+	// Search returns movies matching the terms, most relevant first, and the
+	// total number of matches. Archived movies are never returned.
+	Search(terms []string, limit, offset int) ([]Movie, int64, error)
 	GetAllGenres() ([]Genre, error)
+	GetShowtimeByID(id int64) (*Showtime, error)
 	Create(movie *Movie) error
+	Update(movie *Movie) error
+	Delete(id int64) error
+	// IsInUse reports whether the movie has showtimes in the future or
+	// tickets that are still active.
+	IsInUse(id int64) (bool, error)
+	HasTickets(id int64) (bool, error)
+
+	GetGenreByID(id int64) (*Genre, error)
+	GetGenresByIDs(ids []int64) ([]Genre, error)
+	CreateGenre(genre *Genre) error
+	UpdateGenre(genre *Genre) error
+	DeleteGenre(id int64) error
+	ReplaceGenres(movieID int64, genres []Genre) error
+
+	AddCastMember(cast *CastMember) error
+	DeleteCastMember(movieID, castID int64) error
+	// ReorderCast sets each cast member's Position to its index in castIDs.
+	ReorderCast(movieID int64, castIDs []int64) error
+
+	GetPoster(movieID int64) (*MoviePoster, error)
+	// GetMoviesMissingPoster returns the movies, archived ones aside, with a
+	// PosterURL whose image is not stored yet.
+	GetMoviesMissingPoster() ([]Movie, error)
+	// SavePoster stores the poster, replacing the movie's previous one.
+	SavePoster(poster *MoviePoster) error
+	DeletePoster(movieID int64) error
+
+	// CreateShowtimes inserts all showtimes for one theater, or none of them
+	// if any would overlap an existing showtime there. Each showtime occupies
+	// the theater for its movie's duration plus buffer. Overlaps are reported
+	// as a *ShowtimeConflictError.
+	CreateShowtimes(theaterID int64, showtimes []Showtime, duration, buffer time.Duration) error
+
+	// GetUpcomingShowtimes returns matching showtimes with their Movie, Cinema
+	// and Theater, earliest first.
+	GetUpcomingShowtimes(filter ShowtimeFilter) ([]Showtime, error)
+	// CountTakenSeats returns, per showtime, how many seats are booked or held.
+	CountTakenSeats(showtimeIDs []int64) (map[int64]int, error)
+	// CountBookableSeats returns, per theater, how many seats of its seat map
+	// are not blocked. Theaters without a seat map are missing from the result.
+	CountBookableSeats(theaterIDs []int64) (map[int64]int, error)
+
+	// ReleaseDue moves coming_soon movies whose release date is on or before
+	// today, as a date in today's location, to now_showing and returns how
+	// many moved.
+	ReleaseDue(today time.Time) (int64, error)
 }
@@ -1,7 +1,10 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 )
 
 type GenreResponse struct {
@@ -9,6 +12,89 @@ type GenreResponse struct {
 	Name string `json:"name"`
 }
 
+type MovieRequest struct {
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description"`
+	Duration    int    `json:"duration" validate:"required,gt=0,lte=600"` // in minutes
+	PosterURL   string `json:"poster_url" validate:"omitempty,url,max=255"`
+	ReleaseDate string `json:"release_date" validate:"required,datetime=2006-01-02"`
+	Status      string `json:"status" validate:"required,oneof=now_showing coming_soon archived"`
+	// AgeCertification is one of SU, 13+, 17+ or 21+; empty when not yet classified
+	AgeCertification string  `json:"age_certification" validate:"omitempty,oneof=SU 13+ 17+ 21+"`
+	GenreIDs         []int64 `json:"genre_ids" validate:"dive,gt=0"`
+}
+
+type GenreRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type MovieGenresRequest struct {
+	GenreIDs []int64 `json:"genre_ids" validate:"dive,gt=0"`
+}
+
+type CastMemberRequest struct {
+	Name          string `json:"name" validate:"required,max=255"`
+	Role          string `json:"role" validate:"required,max=50"`
+	CharacterName string `json:"character_name" validate:"max=255"`
+	PhotoURL      string `json:"photo_url" validate:"omitempty,url,max=255"`
+}
+
+type ReorderCastRequest struct {
+	CastIDs []int64 `json:"cast_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+type ScheduleShowtimeRequest struct {
+	MovieID   int64  `json:"movie_id" validate:"required,gt=0"`
+	TheaterID int64  `json:"theater_id" validate:"required,gt=0"`
+	StartTime string `json:"start_time" validate:"required,datetime=2006-01-02T15:04:05Z07:00"` // RFC3339
+}
+
+// BulkScheduleRequest schedules the movie at every given time of day on every
+// date from StartDate to EndDate inclusive.
+type BulkScheduleRequest struct {
+	MovieID   int64    `json:"movie_id" validate:"required,gt=0"`
+	TheaterID int64    `json:"theater_id" validate:"required,gt=0"`
+	StartDate string   `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string   `json:"end_date" validate:"required,datetime=2006-01-02"`
+	Times     []string `json:"times" validate:"required,min=1,dive,datetime=15:04"` // e.g. ["13:00", "16:00", "19:00"]
+}
+
+type ScheduledShowtimeResponse struct {
+	ID        int64     `json:"id"`
+	MovieID   int64     `json:"movie_id"`
+	CinemaID  int64     `json:"cinema_id"`
+	TheaterID int64     `json:"theater_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"` // Movie end, excluding the cleaning buffer
+}
+
+type ShowtimeConflictResponse struct {
+	Error     string                    `json:"error"`
+	Conflicts []domain.ShowtimeConflict `json:"conflicts"`
+}
+
+// MovieListQuery holds the filters and sort of GET /movies. Genres and
+// Certification take comma-separated lists and match any of their values.
+type MovieListQuery struct {
+	// Category is the legacy filter: a status or a single genre name
+	Category      string  `query:"category"`
+	Status        string  `query:"status" validate:"omitempty,oneof=now_showing coming_soon all"`
+	Genres        string  `query:"genres"`
+	ReleasedFrom  string  `query:"released_from" validate:"omitempty,datetime=2006-01-02"`
+	ReleasedTo    string  `query:"released_to" validate:"omitempty,datetime=2006-01-02"`
+	MinRating     float64 `query:"min_rating" validate:"gte=0,lte=10"`
+	MaxDuration   int     `query:"max_duration" validate:"gte=0"` // in minutes
+	Certification string  `query:"certification"`
+	City          string  `query:"city"`
+	Date          string  `query:"date" validate:"omitempty,datetime=2006-01-02"` // Playing on, cinema-local
+	Sort          string  `query:"sort" validate:"omitempty,oneof=rating release_date title popularity"`
+	// Cursor pages by cursor instead of Page; empty for the first page
+	Cursor string `query:"cursor"`
+	Order  string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Page   int    `query:"page"`
+	Limit  int    `query:"limit"`
+}
+
 type BannerResponse struct {
 	MovieID   int64    `json:"movie_id"`
 	Title     string   `json:"title"`
@@ -17,9 +103,12 @@ type BannerResponse struct {
 	Genres    []string `json:"genres"`
 }
 
+// MovieListResponse carries Meta in page/limit mode and Cursor in cursor
+// mode.
 type MovieListResponse struct {
-	Movies []MovieResponse `json:"movies"`
-	Meta   PaginationMeta  `json:"meta"`
+	Movies []MovieResponse  `json:"movies"`
+	Meta   *PaginationMeta  `json:"meta,omitempty"`
+	Cursor *pagination.Meta `json:"cursor,omitempty"`
 }
 
 type PaginationMeta struct {
@@ -35,24 +124,28 @@ type MovieResponse struct {
 	Description string   `json:"description,omitempty"` // Optional for list view
 	Duration    int      `json:"duration"`
 	Rating      float64  `json:"rating"`
+	RatingCount int      `json:"rating_count"`
 	PosterURL   string   `json:"poster_url"`
 	Genres      []string `json:"genres,omitempty"`
 }
 
 type MovieDetailResponse struct {
-	ID          int64              `json:"id"`
-	Title       string             `json:"title"`
-	Description string             `json:"description"`
-	Duration    int                `json:"duration"`
-	Rating      float64            `json:"rating"`
-	PosterURL   string             `json:"poster_url"`
-	ReleaseDate string             `json:"release_date"`
-	Genres      []string           `json:"genres"`
-	Cast        []CastResponse     `json:"cast"`
-	Showtimes   []ShowtimeResponse `json:"showtimes"`
+	ID               int64              `json:"id"`
+	Title            string             `json:"title"`
+	Description      string             `json:"description"`
+	Duration         int                `json:"duration"`
+	Rating           float64            `json:"rating"`
+	RatingCount      int                `json:"rating_count"`
+	AgeCertification string             `json:"age_certification"`
+	PosterURL        string             `json:"poster_url"`
+	ReleaseDate      string             `json:"release_date"`
+	Genres           []string           `json:"genres"`
+	Cast             []CastResponse     `json:"cast"`
+	Showtimes        []ShowtimeResponse `json:"showtimes"`
 }
 
 type CastResponse struct {
+	ID            int64  `json:"id"`
 	Name          string `json:"name"`
 	Role          string `json:"role"`
 	CharacterName string `json:"character_name"`
@@ -60,9 +153,63 @@ type CastResponse struct {
 }
 
 type ShowtimeResponse struct {
+	ID        int64   `json:"id"`
 	StartTime string  `json:"start_time"`
-	Price     float64 `json:"price"`
+	Price     float64 `json:"price"` // Price from, i.e. a standard seat
 	Date      string  `json:"date"`
+	TimeZone  string  `json:"time_zone"` // The cinema's, which StartTime and Date are in
+}
+
+// MovieShowtimesResponse lists where a movie plays, grouped by cinema and
+// then by format.
+type MovieShowtimesResponse struct {
+	MovieID int64             `json:"movie_id"`
+	Title   string            `json:"title"`
+	Cinemas []CinemaShowtimes `json:"cinemas"`
+}
+
+type CinemaShowtimes struct {
+	CinemaID int64             `json:"cinema_id"`
+	Name     string            `json:"name"`
+	City     string            `json:"city"`
+	Address  string            `json:"address"`
+	TimeZone string            `json:"time_zone"`
+	Date     string            `json:"date"` // Local to the cinema
+	Formats  []FormatShowtimes `json:"formats"`
+}
+
+// CinemaScheduleResponse lists the movies playing at one cinema on a day.
+type CinemaScheduleResponse struct {
+	CinemaID int64           `json:"cinema_id"`
+	Name     string          `json:"name"`
+	City     string          `json:"city"`
+	TimeZone string          `json:"time_zone"`
+	Date     string          `json:"date"`
+	Movies   []MovieSchedule `json:"movies"`
+}
+
+type MovieSchedule struct {
+	MovieID          int64             `json:"movie_id"`
+	Title            string            `json:"title"`
+	PosterURL        string            `json:"poster_url"`
+	Duration         int               `json:"duration"`
+	AgeCertification string            `json:"age_certification"`
+	Formats          []FormatShowtimes `json:"formats"`
+}
+
+type FormatShowtimes struct {
+	Format    string         `json:"format"` // Theater type, e.g. "Regular", "IMAX"
+	Showtimes []ShowtimeSlot `json:"showtimes"`
+}
+
+type ShowtimeSlot struct {
+	ID             int64     `json:"id"` // For GET /showtimes/:id/seats
+	TheaterID      int64     `json:"theater_id"`
+	TheaterName    string    `json:"theater_name"`
+	StartTime      time.Time `json:"start_time"` // In the cinema's time zone
+	Time           string    `json:"time"`       // e.g. "19:30"
+	RemainingSeats int       `json:"remaining_seats"`
+	PriceFrom      float64   `json:"price_from"` // A standard seat
 }
 
 func ToMovieResponse(m domain.Movie) MovieResponse {
@@ -71,12 +218,13 @@ func ToMovieResponse(m domain.Movie) MovieResponse {
 		genres[i] = g.Name
 	}
 	return MovieResponse{
-		ID:        m.ID,
-		Title:     m.Title,
-		Duration:  m.Duration,
-		Rating:    m.Rating,
-		PosterURL: m.PosterURL,
-		Genres:    genres,
+		ID:          m.ID,
+		Title:       m.Title,
+		Duration:    m.Duration,
+		Rating:      m.Rating,
+		RatingCount: m.RatingCount,
+		PosterURL:   m.PosterURL,
+		Genres:      genres,
 	}
 }
 
@@ -89,6 +237,7 @@ func ToMovieDetailResponse(m *domain.Movie) *MovieDetailResponse {
 	cast := make([]CastResponse, len(m.Cast))
 	for i, c := range m.Cast {
 		cast[i] = CastResponse{
+			ID:            c.ID,
 			Name:          c.Name,
 			Role:          c.Role,
 			CharacterName: c.CharacterName,
@@ -98,23 +247,29 @@ func ToMovieDetailResponse(m *domain.Movie) *MovieDetailResponse {
 
 	showtimes := make([]ShowtimeResponse, len(m.Showtimes))
 	for i, s := range m.Showtimes {
+		// Shown as printed at the venue, not in the server's zone
+		local := s.StartTime.In(s.Cinema.Location())
 		showtimes[i] = ShowtimeResponse{
-			StartTime: s.StartTime.Format("15:04"),
+			ID:        s.ID,
+			StartTime: local.Format("15:04"),
 			Price:     s.Cinema.BasePrice,
-			Date:      s.StartTime.Format("2006-01-02"),
+			Date:      local.Format("2006-01-02"),
+			TimeZone:  local.Location().String(),
 		}
 	}
 
 	return &MovieDetailResponse{
-		ID:          m.ID,
-		Title:       m.Title,
-		Description: m.Description,
-		Duration:    m.Duration,
-		Rating:      m.Rating,
-		PosterURL:   m.PosterURL,
-		ReleaseDate: m.ReleaseDate.Format("2006-01-02"),
-		Genres:      genres,
-		Cast:        cast,
-		Showtimes:   showtimes,
+		ID:               m.ID,
+		Title:            m.Title,
+		Description:      m.Description,
+		Duration:         m.Duration,
+		Rating:           m.Rating,
+		RatingCount:      m.RatingCount,
+		AgeCertification: m.AgeCertification,
+		PosterURL:        m.PosterURL,
+		ReleaseDate:      m.ReleaseDate.Format("2006-01-02"),
+		Genres:           genres,
+		Cast:             cast,
+		Showtimes:        showtimes,
 	}
 }
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
+	"github.com/gofiber/fiber/v2"
+)
+
+// registerAdminRoutes mounts the content management routes. Each group gets
+// its own prefix because group middleware applies to everything below it.
+func (h *MovieHandler) registerAdminRoutes(app *fiber.App, auth, requireAdmin fiber.Handler) {
+	movies := app.Group("/admin/movies", auth, requireAdmin)
+	movies.Post("/", h.handleCreateMovie)
+	movies.Put("/:id", h.handleUpdateMovie)
+	movies.Post("/:id/archive", h.handleArchiveMovie)
+	movies.Delete("/:id", h.handleDeleteMovie)
+	movies.Put("/:id/genres", h.handleSetMovieGenres)
+	movies.Post("/:id/cast", h.handleAddCastMember)
+	movies.Put("/:id/cast/order", h.handleReorderCast)
+	movies.Delete("/:id/cast/:castId", h.handleRemoveCastMember)
+
+	showtimes := app.Group("/admin/showtimes", auth, requireAdmin)
+	showtimes.Post("/", h.handleScheduleShowtime)
+	showtimes.Post("/bulk", h.handleBulkScheduleShowtimes)
+
+	genres := app.Group("/admin/genres", auth, requireAdmin)
+	genres.Post("/", h.handleCreateGenre)
+	genres.Put("/:id", h.handleUpdateGenre)
+	genres.Delete("/:id", h.handleDeleteGenre)
+}
+
+func (h *MovieHandler) handleCreateMovie(c *fiber.Ctx) error {
+	var req dto.MovieRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateMovie(req)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *MovieHandler) handleUpdateMovie(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.MovieRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.UpdateMovie(id, req)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleArchiveMovie(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.ArchiveMovie(id)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleDeleteMovie(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.DeleteMovie(id); err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *MovieHandler) handleSetMovieGenres(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.MovieGenresRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.SetMovieGenres(id, req.GenreIDs)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleAddCastMember(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.CastMemberRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.AddCastMember(id, req)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *MovieHandler) handleReorderCast(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.ReorderCastRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.ReorderCast(id, req.CastIDs)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleRemoveCastMember(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	castID, err := strconv.ParseInt(c.Params("castId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid cast ID")
+	}
+
+	if err := h.Service.RemoveCastMember(id, castID); err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *MovieHandler) handleCreateGenre(c *fiber.Ctx) error {
+	var req dto.GenreRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateGenre(req.Name)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *MovieHandler) handleUpdateGenre(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.GenreRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.UpdateGenre(id, req.Name)
+	if err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleDeleteGenre(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.DeleteGenre(id); err != nil {
+		return c.Status(adminErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *MovieHandler) handleScheduleShowtime(c *fiber.Ctx) error {
+	var req dto.ScheduleShowtimeRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.ScheduleShowtime(req)
+	if err != nil {
+		return h.sendScheduleError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *MovieHandler) handleBulkScheduleShowtimes(c *fiber.Ctx) error {
+	var req dto.BulkScheduleRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.BulkScheduleShowtimes(req)
+	if err != nil {
+		return h.sendScheduleError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// sendScheduleError lists every overlap so programmers can fix the whole
+// schedule in one go.
+func (h *MovieHandler) sendScheduleError(c *fiber.Ctx, err error) error {
+	var conflictErr *domain.ShowtimeConflictError
+	if errors.As(err, &conflictErr) {
+		return c.Status(fiber.StatusConflict).JSON(dto.ShowtimeConflictResponse{
+			Error:     conflictErr.Error(),
+			Conflicts: conflictErr.Conflicts,
+		})
+	}
+	return c.Status(adminErrorStatus(err)).SendString(err.Error())
+}
+
+func (h *MovieHandler) parseAndValidate(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+	return h.Validator.Struct(req)
+}
+
+func adminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrMovieNotFound),
+		errors.Is(err, domain.ErrGenreNotFound),
+		errors.Is(err, domain.ErrCastMemberNotFound),
+		errors.Is(err, cinemaDomain.ErrTheaterNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidCastOrder),
+		errors.Is(err, domain.ErrInvalidSchedule),
+		errors.Is(err, domain.ErrShowtimeInPast),
+		errors.Is(err, domain.ErrInvalidPoster):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrGenreExists),
+		errors.Is(err, domain.ErrMovieInUse),
+		errors.Is(err, domain.ErrMovieHasTickets):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
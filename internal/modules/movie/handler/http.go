@@ -1,26 +1,41 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/service"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type MovieHandler struct {
-	Service *service.MovieService
+	Service   *service.MovieService
+	Validator *validator.Validate
 }
 
-func NewMovieHandler(s *service.MovieService) *MovieHandler {
-	return &MovieHandler{Service: s}
+func NewMovieHandler(s *service.MovieService, v *validator.Validate) *MovieHandler {
+	return &MovieHandler{Service: s, Validator: v}
 }
 
-func (h *MovieHandler) RegisterRoutes(app *fiber.App) {
+func (h *MovieHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
 	movies := app.Group("/movies")
 	movies.Get("/categories", h.handleGetCategories)
 	movies.Get("/banner", h.handleGetBanner)
 	movies.Get("/", h.handleGetMovies) // List with query param
+	movies.Get("/search", h.handleSearch)
 	movies.Get("/:id", h.handleDetail)
+	movies.Get("/:id/showtimes", h.handleGetMovieShowtimes)
+	app.Get("/cinemas/:id/showtimes", h.handleGetCinemaSchedule)
+
+	// Content management
+	h.registerAdminRoutes(app, auth, middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin))
 }
 
 func (h *MovieHandler) handleGetCategories(c *fiber.Ctx) error {
@@ -43,12 +58,34 @@ func (h *MovieHandler) handleGetBanner(c *fiber.Ctx) error {
 }
 
 func (h *MovieHandler) handleGetMovies(c *fiber.Ctx) error {
-	category := c.Query("category")
+	var q dto.MovieListQuery
+	if err := c.QueryParser(&q); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(q); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	getMovies := h.Service.GetMovies
+	if c.Context().QueryArgs().Has("cursor") {
+		getMovies = h.Service.GetMoviesByCursor
+	}
+	resp, err := getMovies(q)
+	if err != nil {
+		return c.Status(listingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleSearch(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	limit, _ := strconv.Atoi(c.Query("limit", "10"))
 
-	resp, err := h.Service.GetMovies(category, page, limit)
+	resp, err := h.Service.SearchMovies(c.Query("q"), page, limit)
 	if err != nil {
+		if errors.Is(err, domain.ErrEmptySearchQuery) {
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
 	return c.JSON(resp)
@@ -63,7 +100,49 @@ func (h *MovieHandler) handleDetail(c *fiber.Ctx) error {
 
 	resp, err := h.Service.GetDetail(id)
 	if err != nil {
+		if errors.Is(err, domain.ErrMovieNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
 	}
 	return c.JSON(resp)
 }
+
+func (h *MovieHandler) handleGetMovieShowtimes(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetMovieShowtimes(id, c.Query("city"), c.Query("date"))
+	if err != nil {
+		return c.Status(listingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *MovieHandler) handleGetCinemaSchedule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetCinemaSchedule(id, c.Query("date"))
+	if err != nil {
+		return c.Status(listingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func listingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidDate),
+		errors.Is(err, domain.ErrInvalidMovieFilter),
+		errors.Is(err, pagination.ErrInvalidCursor):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrMovieNotFound), errors.Is(err, cinemaDomain.ErrCinemaNotFound):
+		return fiber.StatusNotFound
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -2,10 +2,15 @@ package repository
 
 import (
 	"errors"
-	"fmt"
+	"strings"
+	"time"
 
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PostgresMovieRepository struct {
@@ -26,48 +31,100 @@ func (r *PostgresMovieRepository) GetAll() ([]domain.Movie, error) {
 
 func (r *PostgresMovieRepository) GetByID(id int64) (*domain.Movie, error) {
 	var movie domain.Movie
-	if err := r.DB.Preload("Genres").Preload("Cast").Preload("Showtimes.Cinema").First(&movie, id).Error; err != nil {
+	if err := r.DB.Preload("Genres").Preload("Cast", orderCast).Preload("Showtimes.Cinema").Preload("Showtimes.Theater").First(&movie, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("movie not found")
+			return nil, domain.ErrMovieNotFound
 		}
 		return nil, err
 	}
 	return &movie, nil
 }
 
-func (r *PostgresMovieRepository) GetByStatus(status string, limit, offset int) ([]domain.Movie, int64, error) {
-	var movies []domain.Movie
-	var total int64
+// movieSorts whitelists the sort keys; an empty key lists movies in the
+// order they were added.
+var movieSorts = map[string]pagination.Order{
+	"":                     {Name: "added", Key: "movies.id", KeyType: "bigint"},
+	domain.SortRating:      {Name: domain.SortRating, Key: "movies.rating", KeyType: "numeric"},
+	domain.SortReleaseDate: {Name: domain.SortReleaseDate, Key: "movies.release_date", KeyType: "date"},
+	domain.SortTitle:       {Name: domain.SortTitle, Key: "LOWER(movies.title)", KeyType: "text"},
+	domain.SortPopularity: {
+		Name:    domain.SortPopularity,
+		Key:     "(SELECT COUNT(*) FROM tickets WHERE tickets.movie_id = movies.id AND tickets.status IN ('active', 'completed'))",
+		KeyType: "bigint",
+	},
+}
 
-	// Count total
-	if err := r.DB.Model(&domain.Movie{}).Where("status = ?", status).Count(&total).Error; err != nil {
-		return nil, 0, err
+func movieOrder(filter domain.MovieFilter) (pagination.Order, error) {
+	order, ok := movieSorts[filter.Sort]
+	if !ok {
+		return order, domain.ErrInvalidMovieFilter
 	}
+	order.ID = "movies.id"
+	order.Desc = filter.Descending
+	return order, nil
+}
 
-	if err := r.DB.Where("status = ?", status).Limit(limit).Offset(offset).Preload("Genres").Find(&movies).Error; err != nil {
-		return nil, 0, err
+// filtered selects the movies matching filter.
+func (r *PostgresMovieRepository) filtered(filter domain.MovieFilter) *gorm.DB {
+	q := r.DB.Model(&domain.Movie{}).Where("movies.status <> ?", domain.StatusArchived)
+	if filter.Status != "" {
+		q = q.Where("movies.status = ?", filter.Status)
 	}
-	return movies, total, nil
+	if len(filter.Genres) > 0 {
+		names := make([]string, len(filter.Genres))
+		for i, g := range filter.Genres {
+			names[i] = strings.ToLower(g)
+		}
+		q = q.Where(`EXISTS (SELECT 1 FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id
+			WHERE movie_genres.movie_id = movies.id AND LOWER(genres.name) IN ?)`, names)
+	}
+	if filter.ReleasedFrom != nil {
+		q = q.Where("movies.release_date >= ?", *filter.ReleasedFrom)
+	}
+	if filter.ReleasedTo != nil {
+		q = q.Where("movies.release_date <= ?", *filter.ReleasedTo)
+	}
+	if filter.MinRating > 0 {
+		q = q.Where("movies.rating >= ?", filter.MinRating)
+	}
+	if filter.MaxDuration > 0 {
+		q = q.Where("movies.duration <= ?", filter.MaxDuration)
+	}
+	if len(filter.Certifications) > 0 {
+		q = q.Where("movies.age_certification IN ?", filter.Certifications)
+	}
+	if filter.City != "" || filter.PlayingOn != "" {
+		playing := `EXISTS (SELECT 1 FROM showtimes JOIN cinemas ON cinemas.id = showtimes.cinema_id
+			WHERE showtimes.movie_id = movies.id AND showtimes.start_time > ?`
+		args := []interface{}{time.Now()}
+		if filter.City != "" {
+			playing += " AND LOWER(cinemas.city) = LOWER(?)"
+			args = append(args, filter.City)
+		}
+		if filter.PlayingOn != "" {
+			// The date is the cinema's local date, not the database's
+			playing += " AND (showtimes.start_time AT TIME ZONE cinemas.time_zone)::date = ?"
+			args = append(args, filter.PlayingOn)
+		}
+		q = q.Where(playing+")", args...)
+	}
+	return q
 }
 
-func (r *PostgresMovieRepository) GetByGenre(genreName string, limit, offset int) ([]domain.Movie, int64, error) {
-	var movies []domain.Movie
-	var total int64
-
-	// Count total (need proper join for count too)
-	err := r.DB.Model(&domain.Movie{}).
-		Joins("JOIN movie_genres ON movie_genres.movie_id = movies.id").
-		Joins("JOIN genres ON genres.id = movie_genres.genre_id").
-		Where("genres.name = ?", genreName).
-		Count(&total).Error
+func (r *PostgresMovieRepository) List(filter domain.MovieFilter, limit, offset int) ([]domain.Movie, int64, error) {
+	order, err := movieOrder(filter)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Fetch data
-	err = r.DB.Joins("JOIN movie_genres ON movie_genres.movie_id = movies.id").
-		Joins("JOIN genres ON genres.id = movie_genres.genre_id").
-		Where("genres.name = ?", genreName).
+	var total int64
+	if err := r.filtered(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var movies []domain.Movie
+	err = r.filtered(filter).
+		Order(order.SQL()).
 		Limit(limit).Offset(offset).
 		Preload("Genres").
 		Find(&movies).Error
@@ -77,6 +134,28 @@ func (r *PostgresMovieRepository) GetByGenre(genreName string, limit, offset int
 	return movies, total, nil
 }
 
+func (r *PostgresMovieRepository) ListByCursor(filter domain.MovieFilter, cursor string, limit int) ([]domain.Movie, pagination.Meta, error) {
+	order, err := movieOrder(filter)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	after, err := pagination.Decode(cursor, order)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+
+	ids, meta, err := pagination.Keyset(r.filtered(filter), order, after, limit)
+	if err != nil || len(ids) == 0 {
+		return nil, meta, err
+	}
+
+	var movies []domain.Movie
+	if err := r.DB.Preload("Genres").Find(&movies, ids).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return pagination.Arrange(movies, ids, func(m domain.Movie) int64 { return m.ID }), meta, nil
+}
+
 func (r *PostgresMovieRepository) GetAllGenres() ([]domain.Genre, error) {
 	var genres []domain.Genre
 	if err := r.DB.Find(&genres).Error; err != nil {
@@ -85,6 +164,407 @@ func (r *PostgresMovieRepository) GetAllGenres() ([]domain.Genre, error) {
 	return genres, nil
 }
 
+func (r *PostgresMovieRepository) GetShowtimeByID(id int64) (*domain.Showtime, error) {
+	var showtime domain.Showtime
+	if err := r.DB.Preload("Cinema").Preload("Theater").First(&showtime, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrShowtimeNotFound
+		}
+		return nil, err
+	}
+	return &showtime, nil
+}
+
 func (r *PostgresMovieRepository) Create(movie *domain.Movie) error {
-	return r.DB.Create(movie).Error
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(movie).Error; err != nil {
+			return err
+		}
+		return refreshSearchKeywords(tx, "id = ?", movie.ID)
+	})
+}
+
+func (r *PostgresMovieRepository) Update(movie *domain.Movie) error {
+	// Select forces zero values (e.g. an emptied description) to be written too
+	result := r.DB.Model(movie).
+		Select("title", "description", "duration", "poster_url", "release_date", "status", "age_certification").
+		Omit(clause.Associations).
+		Updates(movie)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrMovieNotFound
+	}
+	return nil
+}
+
+func (r *PostgresMovieRepository) Delete(id int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("movie_id = ?", id).Delete(&domain.CastMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&domain.Movie{ID: id}).Association("Genres").Clear(); err != nil {
+			return err
+		}
+		// Only past showtimes can be left at this point, see IsInUse
+		if err := tx.Where("movie_id = ?", id).Delete(&domain.Showtime{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("movie_id = ?", id).Delete(&domain.MoviePoster{}).Error; err != nil {
+			return err
+		}
+		// Watchlists and on-sale alerts go with the movie
+		if err := tx.Exec("DELETE FROM watchlist_items WHERE movie_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM on_sale_alerts WHERE movie_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&domain.Movie{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrMovieNotFound
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMovieRepository) GetUpcomingShowtimes(filter domain.ShowtimeFilter) ([]domain.Showtime, error) {
+	from := time.Now()
+	if filter.From.After(from) {
+		from = filter.From
+	}
+
+	query := r.DB.Model(&domain.Showtime{}).
+		Joins("JOIN movies ON movies.id = showtimes.movie_id AND movies.status <> ?", domain.StatusArchived).
+		Where("showtimes.start_time > ?", from)
+	if !filter.To.IsZero() {
+		query = query.Where("showtimes.start_time < ?", filter.To)
+	}
+	if filter.MovieID != 0 {
+		query = query.Where("showtimes.movie_id = ?", filter.MovieID)
+	}
+	if filter.CinemaID != 0 {
+		query = query.Where("showtimes.cinema_id = ?", filter.CinemaID)
+	}
+	if filter.City != "" {
+		query = query.Joins("JOIN cinemas ON cinemas.id = showtimes.cinema_id").
+			Where("LOWER(cinemas.city) = LOWER(?)", filter.City)
+	}
+
+	var showtimes []domain.Showtime
+	if err := query.Preload("Movie").Preload("Cinema").Preload("Theater").
+		Order("showtimes.start_time").Find(&showtimes).Error; err != nil {
+		return nil, err
+	}
+	return showtimes, nil
+}
+
+func (r *PostgresMovieRepository) CountTakenSeats(showtimeIDs []int64) (map[int64]int, error) {
+	taken := make(map[int64]int, len(showtimeIDs))
+	if len(showtimeIDs) == 0 {
+		return taken, nil
+	}
+
+	type row struct {
+		ShowtimeID int64
+		Count      int
+	}
+	// Seats and holds live in the ticket module, which depends on this one
+	var booked, held []row
+	if err := r.DB.Table("ticket_seats").Select("showtime_id, COUNT(*) AS count").
+		Where("showtime_id IN ? AND cancelled = ?", showtimeIDs, false).
+		Group("showtime_id").Scan(&booked).Error; err != nil {
+		return nil, err
+	}
+	if err := r.DB.Table("seat_holds").Select("showtime_id, COUNT(*) AS count").
+		Where("showtime_id IN ? AND expires_at > ?", showtimeIDs, time.Now()).
+		Group("showtime_id").Scan(&held).Error; err != nil {
+		return nil, err
+	}
+	for _, rows := range [][]row{booked, held} {
+		for _, r := range rows {
+			taken[r.ShowtimeID] += r.Count
+		}
+	}
+	return taken, nil
+}
+
+func (r *PostgresMovieRepository) CountBookableSeats(theaterIDs []int64) (map[int64]int, error) {
+	seats := make(map[int64]int, len(theaterIDs))
+	if len(theaterIDs) == 0 {
+		return seats, nil
+	}
+
+	var rows []struct {
+		TheaterID int64
+		Bookable  int
+	}
+	// FILTER keeps theaters whose seats are all blocked in the result
+	if err := r.DB.Model(&cinemaDomain.TheaterSeat{}).
+		Select("theater_id, COUNT(*) FILTER (WHERE NOT blocked) AS bookable").
+		Where("theater_id IN ?", theaterIDs).
+		Group("theater_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		seats[r.TheaterID] = r.Bookable
+	}
+	return seats, nil
+}
+
+func (r *PostgresMovieRepository) IsInUse(id int64) (bool, error) {
+	var count int64
+	if err := r.DB.Model(&domain.Showtime{}).
+		Where("movie_id = ? AND start_time > ?", id, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	// Tickets live in the ticket module, which depends on this one
+	if err := r.DB.Table("tickets").
+		Where("movie_id = ? AND status IN ?", id, []string{"active", "pending_payment"}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PostgresMovieRepository) HasTickets(id int64) (bool, error) {
+	var count int64
+	if err := r.DB.Table("tickets").Where("movie_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *PostgresMovieRepository) GetGenreByID(id int64) (*domain.Genre, error) {
+	var genre domain.Genre
+	if err := r.DB.First(&genre, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrGenreNotFound
+		}
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (r *PostgresMovieRepository) GetGenresByIDs(ids []int64) ([]domain.Genre, error) {
+	var genres []domain.Genre
+	if len(ids) == 0 {
+		return genres, nil
+	}
+	if err := r.DB.Where("id IN ?", ids).Find(&genres).Error; err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (r *PostgresMovieRepository) CreateGenre(genre *domain.Genre) error {
+	if err := r.DB.Create(genre).Error; err != nil {
+		if isUniqueViolation(err) {
+			return domain.ErrGenreExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresMovieRepository) UpdateGenre(genre *domain.Genre) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(genre).Update("name", genre.Name)
+		if result.Error != nil {
+			if isUniqueViolation(result.Error) {
+				return domain.ErrGenreExists
+			}
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrGenreNotFound
+		}
+		return refreshSearchKeywords(tx, "id IN (SELECT movie_id FROM movie_genres WHERE genre_id = ?)", genre.ID)
+	})
+}
+
+func (r *PostgresMovieRepository) DeleteGenre(id int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var movieIDs []int64
+		if err := tx.Table("movie_genres").Where("genre_id = ?", id).Pluck("movie_id", &movieIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM movie_genres WHERE genre_id = ?", id).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&domain.Genre{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrGenreNotFound
+		}
+		if len(movieIDs) == 0 {
+			return nil
+		}
+		return refreshSearchKeywords(tx, "id IN ?", movieIDs)
+	})
+}
+
+func (r *PostgresMovieRepository) ReplaceGenres(movieID int64, genres []domain.Genre) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&domain.Movie{ID: movieID}).Association("Genres").Replace(genres); err != nil {
+			return err
+		}
+		return refreshSearchKeywords(tx, "id = ?", movieID)
+	})
+}
+
+func (r *PostgresMovieRepository) AddCastMember(cast *domain.CastMember) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// Append to the end of the billing order
+		var maxPosition *int
+		if err := tx.Model(&domain.CastMember{}).
+			Where("movie_id = ?", cast.MovieID).
+			Select("MAX(position)").
+			Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+		if maxPosition != nil {
+			cast.Position = *maxPosition + 1
+		}
+		if err := tx.Create(cast).Error; err != nil {
+			return err
+		}
+		return refreshSearchKeywords(tx, "id = ?", cast.MovieID)
+	})
+}
+
+func (r *PostgresMovieRepository) DeleteCastMember(movieID, castID int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND movie_id = ?", castID, movieID).Delete(&domain.CastMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrCastMemberNotFound
+		}
+		return refreshSearchKeywords(tx, "id = ?", movieID)
+	})
+}
+
+func (r *PostgresMovieRepository) GetPoster(movieID int64) (*domain.MoviePoster, error) {
+	var poster domain.MoviePoster
+	if err := r.DB.First(&poster, "movie_id = ?", movieID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPosterNotFound
+		}
+		return nil, err
+	}
+	return &poster, nil
+}
+
+func (r *PostgresMovieRepository) GetMoviesMissingPoster() ([]domain.Movie, error) {
+	var movies []domain.Movie
+	err := r.DB.Where("status <> ? AND poster_url <> ''", domain.StatusArchived).
+		Where("NOT EXISTS (SELECT 1 FROM movie_posters WHERE movie_posters.movie_id = movies.id AND movie_posters.url = movies.poster_url)").
+		Order("id").Find(&movies).Error
+	if err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
+
+func (r *PostgresMovieRepository) SavePoster(poster *domain.MoviePoster) error {
+	return r.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(poster).Error
+}
+
+func (r *PostgresMovieRepository) DeletePoster(movieID int64) error {
+	return r.DB.Where("movie_id = ?", movieID).Delete(&domain.MoviePoster{}).Error
+}
+
+func (r *PostgresMovieRepository) ReorderCast(movieID int64, castIDs []int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []int64
+		if err := tx.Model(&domain.CastMember{}).Where("movie_id = ?", movieID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+
+		remaining := make(map[int64]bool, len(existing))
+		for _, id := range existing {
+			remaining[id] = true
+		}
+		if len(castIDs) != len(existing) {
+			return domain.ErrInvalidCastOrder
+		}
+		for _, id := range castIDs {
+			if !remaining[id] {
+				return domain.ErrInvalidCastOrder
+			}
+			delete(remaining, id)
+		}
+
+		for position, id := range castIDs {
+			if err := tx.Model(&domain.CastMember{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMovieRepository) CreateShowtimes(theaterID int64, showtimes []domain.Showtime, duration, buffer time.Duration) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize scheduling per theater so concurrent requests can't both pass the check
+		if err := tx.Exec("SELECT id FROM theaters WHERE id = ? FOR UPDATE", theaterID).Error; err != nil {
+			return err
+		}
+
+		var conflicts []domain.ShowtimeConflict
+		for _, st := range showtimes {
+			end := st.StartTime.Add(duration + buffer)
+
+			var existing []domain.Showtime
+			if err := tx.Joins("JOIN movies ON movies.id = showtimes.movie_id").
+				Where("showtimes.theater_id = ?", theaterID).
+				Where("showtimes.start_time < ?", end).
+				Where("showtimes.start_time + movies.duration * interval '1 minute' + ? * interval '1 second' > ?", int64(buffer.Seconds()), st.StartTime).
+				Find(&existing).Error; err != nil {
+				return err
+			}
+			for _, e := range existing {
+				conflicts = append(conflicts, domain.ShowtimeConflict{
+					StartTime:            st.StartTime,
+					ConflictingID:        e.ID,
+					ConflictingStartTime: e.StartTime,
+				})
+			}
+		}
+		if len(conflicts) > 0 {
+			return &domain.ShowtimeConflictError{Conflicts: conflicts}
+		}
+
+		return tx.Create(&showtimes).Error
+	})
+}
+
+func orderCast(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (r *PostgresMovieRepository) ReleaseDue(today time.Time) (int64, error) {
+	result := r.DB.Model(&domain.Movie{}).
+		Where("status = ? AND release_date <= ?", domain.StatusComingSoon, today.Format("2006-01-02")).
+		Update("status", domain.StatusNowShowing)
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"gorm.io/gorm"
+)
+
+// searchSimilarity is the pg_trgm word similarity a title or keyword list
+// needs to match a query that full-text search missed, e.g. a typo.
+const searchSimilarity = 0.4
+
+// EnsureSearchIndex sets up movie search: a weighted tsvector generated from
+// the title (A), genre and cast names (B) and description (C), a GIN index on
+// it, and trigram indexes for typo tolerance. It also resyncs every movie's
+// search keywords. Safe to run on every start.
+func (r *PostgresMovieRepository) EnsureSearchIndex() error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		`ALTER TABLE movies ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(search_keywords, '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'C')
+		) STORED`,
+		"CREATE INDEX IF NOT EXISTS idx_movies_search_vector ON movies USING GIN (search_vector)",
+		"CREATE INDEX IF NOT EXISTS idx_movies_title_trgm ON movies USING GIN (title gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_movies_search_keywords_trgm ON movies USING GIN (search_keywords gin_trgm_ops)",
+	}
+	for _, stmt := range statements {
+		if err := r.DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return refreshSearchKeywords(r.DB, "TRUE")
+}
+
+// Search ranks movies matching every term, as a word prefix in full-text
+// search or, failing that, by trigram similarity to the title or keywords.
+// Terms must be plain words, see domain.SearchTerms.
+func (r *PostgresMovieRepository) Search(terms []string, limit, offset int) ([]domain.Movie, int64, error) {
+	prefixes := make([]string, len(terms))
+	for i, t := range terms {
+		prefixes[i] = t + ":*"
+	}
+	tsquery := strings.Join(prefixes, " & ")
+	text := strings.Join(terms, " ")
+
+	var movies []domain.Movie
+	var total int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// The <% operator uses this threshold and the trigram indexes
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", searchSimilarity)).Error; err != nil {
+			return err
+		}
+
+		matching := func() *gorm.DB {
+			return tx.Model(&domain.Movie{}).
+				Where("movies.status <> ?", domain.StatusArchived).
+				Where("(movies.search_vector @@ to_tsquery('simple', ?) OR ? <% movies.title OR ? <% movies.search_keywords)", tsquery, text, text)
+		}
+		if err := matching().Count(&total).Error; err != nil {
+			return err
+		}
+
+		// Full-text hits outrank fuzzy ones; title similarity breaks ties
+		return matching().
+			Select("movies.*, ts_rank(movies.search_vector, to_tsquery('simple', ?)) * 2 + GREATEST(word_similarity(?, movies.title), 0.8 * word_similarity(?, movies.search_keywords)) AS score", tsquery, text, text).
+			Order("score DESC, movies.id").
+			Limit(limit).Offset(offset).
+			Preload("Genres").
+			Find(&movies).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+// refreshSearchKeywords rebuilds search_keywords, the genre and cast names
+// folded into the search vector, for the movies matching where.
+func refreshSearchKeywords(db *gorm.DB, where string, args ...interface{}) error {
+	return db.Exec(`UPDATE movies SET search_keywords = concat_ws(' ',
+		(SELECT string_agg(genres.name, ' ') FROM movie_genres JOIN genres ON genres.id = movie_genres.genre_id WHERE movie_genres.movie_id = movies.id),
+		(SELECT string_agg(cast_members.name, ' ') FROM cast_members WHERE cast_members.movie_id = movies.id)
+	) WHERE `+where, args...).Error
+}
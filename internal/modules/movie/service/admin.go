@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
+)
+
+func (s *MovieService) CreateMovie(req dto.MovieRequest) (*dto.MovieDetailResponse, error) {
+	movie, err := s.movieFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validPosterURL(req.PosterURL); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.Create(movie); err != nil {
+		return nil, err
+	}
+	return s.GetDetail(movie.ID)
+}
+
+func (s *MovieService) UpdateMovie(id int64, req dto.MovieRequest) (*dto.MovieDetailResponse, error) {
+	if _, err := s.Repo.GetByID(id); err != nil {
+		return nil, err
+	}
+
+	movie, err := s.movieFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validPosterURL(req.PosterURL); err != nil {
+		return nil, err
+	}
+	movie.ID = id
+	if err := s.Repo.Update(movie); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.ReplaceGenres(id, movie.Genres); err != nil {
+		return nil, err
+	}
+	// A changed poster is fetched by FetchPosters; until then the stored one
+	// no longer matches the URL and is left out of documents
+	if req.PosterURL == "" {
+		if err := s.Repo.DeletePoster(id); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetDetail(id)
+}
+
+// ArchiveMovie hides a movie from every listing while keeping its history.
+func (s *MovieService) ArchiveMovie(id int64) (*dto.MovieDetailResponse, error) {
+	movie, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	movie.Status = domain.StatusArchived
+	if err := s.Repo.Update(movie); err != nil {
+		return nil, err
+	}
+	return s.GetDetail(id)
+}
+
+// DeleteMovie permanently removes a movie. Movies that are still scheduled or
+// have been sold must be archived instead.
+func (s *MovieService) DeleteMovie(id int64) error {
+	if _, err := s.Repo.GetByID(id); err != nil {
+		return err
+	}
+
+	inUse, err := s.Repo.IsInUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return domain.ErrMovieInUse
+	}
+
+	hasTickets, err := s.Repo.HasTickets(id)
+	if err != nil {
+		return err
+	}
+	if hasTickets {
+		return domain.ErrMovieHasTickets
+	}
+	return s.Repo.Delete(id)
+}
+
+func (s *MovieService) SetMovieGenres(movieID int64, genreIDs []int64) (*dto.MovieDetailResponse, error) {
+	if _, err := s.Repo.GetByID(movieID); err != nil {
+		return nil, err
+	}
+
+	genres, err := s.resolveGenres(genreIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repo.ReplaceGenres(movieID, genres); err != nil {
+		return nil, err
+	}
+	return s.GetDetail(movieID)
+}
+
+func (s *MovieService) CreateGenre(name string) (*dto.GenreResponse, error) {
+	genre := &domain.Genre{Name: name}
+	if err := s.Repo.CreateGenre(genre); err != nil {
+		return nil, err
+	}
+	return &dto.GenreResponse{ID: genre.ID, Name: genre.Name}, nil
+}
+
+func (s *MovieService) UpdateGenre(id int64, name string) (*dto.GenreResponse, error) {
+	genre := &domain.Genre{ID: id, Name: name}
+	if err := s.Repo.UpdateGenre(genre); err != nil {
+		return nil, err
+	}
+	return &dto.GenreResponse{ID: genre.ID, Name: genre.Name}, nil
+}
+
+func (s *MovieService) DeleteGenre(id int64) error {
+	return s.Repo.DeleteGenre(id)
+}
+
+func (s *MovieService) AddCastMember(movieID int64, req dto.CastMemberRequest) (*dto.MovieDetailResponse, error) {
+	if _, err := s.Repo.GetByID(movieID); err != nil {
+		return nil, err
+	}
+
+	cast := &domain.CastMember{
+		MovieID:       movieID,
+		Name:          req.Name,
+		Role:          req.Role,
+		CharacterName: req.CharacterName,
+		PhotoURL:      req.PhotoURL,
+	}
+	if err := s.Repo.AddCastMember(cast); err != nil {
+		return nil, err
+	}
+	return s.GetDetail(movieID)
+}
+
+func (s *MovieService) ReorderCast(movieID int64, castIDs []int64) (*dto.MovieDetailResponse, error) {
+	if err := s.Repo.ReorderCast(movieID, castIDs); err != nil {
+		return nil, err
+	}
+	return s.GetDetail(movieID)
+}
+
+func (s *MovieService) RemoveCastMember(movieID, castID int64) error {
+	return s.Repo.DeleteCastMember(movieID, castID)
+}
+
+func (s *MovieService) movieFromRequest(req dto.MovieRequest) (*domain.Movie, error) {
+	releaseDate, err := time.Parse("2006-01-02", req.ReleaseDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release date: %w", err)
+	}
+
+	genres, err := s.resolveGenres(req.GenreIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Movie{
+		Title:            req.Title,
+		Description:      req.Description,
+		Duration:         req.Duration,
+		PosterURL:        req.PosterURL,
+		ReleaseDate:      releaseDate,
+		Status:           req.Status,
+		AgeCertification: req.AgeCertification,
+		Genres:           genres,
+	}, nil
+}
+
+// resolveGenres loads the genres by ID and fails if any of them is unknown.
+func (s *MovieService) resolveGenres(ids []int64) ([]domain.Genre, error) {
+	genres, err := s.Repo.GetGenresByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int64]bool, len(genres))
+	for _, g := range genres {
+		found[g.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: %d", domain.ErrGenreNotFound, id)
+		}
+	}
+	return genres, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
+)
+
+const dateLayout = "2006-01-02"
+
+// GetMovieShowtimes lists the movie's upcoming showtimes on date (today when
+// empty) in every cinema, or only in those of city. Every cinema's day runs
+// on its own clock, so "today" may differ between cinemas.
+func (s *MovieService) GetMovieShowtimes(movieID int64, city, date string) (*dto.MovieShowtimesResponse, error) {
+	movie, err := s.Repo.GetByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	if movie.Status == domain.StatusArchived {
+		return nil, domain.ErrMovieNotFound
+	}
+
+	now := time.Now()
+	from, to := now, now.Add(48*time.Hour)
+	if date != "" {
+		day, err := time.Parse(dateLayout, date)
+		if err != nil {
+			return nil, domain.ErrInvalidDate
+		}
+		// Wide enough for the day in any time zone; narrowed per cinema below
+		from, to = day.Add(-14*time.Hour), day.Add(36*time.Hour)
+	}
+
+	showtimes, err := s.Repo.GetUpcomingShowtimes(domain.ShowtimeFilter{MovieID: movieID, City: city, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	var onDay []domain.Showtime
+	for _, st := range showtimes {
+		loc := st.Cinema.Location()
+		want := date
+		if want == "" {
+			want = now.In(loc).Format(dateLayout)
+		}
+		if st.StartTime.In(loc).Format(dateLayout) == want {
+			onDay = append(onDay, st)
+		}
+	}
+
+	slots, err := s.toSlots(onDay)
+	if err != nil {
+		return nil, err
+	}
+
+	byCinema := make(map[int64][]int)
+	var cinemaIDs []int64
+	for i, st := range onDay {
+		if _, ok := byCinema[st.CinemaID]; !ok {
+			cinemaIDs = append(cinemaIDs, st.CinemaID)
+		}
+		byCinema[st.CinemaID] = append(byCinema[st.CinemaID], i)
+	}
+
+	resp := &dto.MovieShowtimesResponse{MovieID: movie.ID, Title: movie.Title, Cinemas: []dto.CinemaShowtimes{}}
+	for _, id := range cinemaIDs {
+		idx := byCinema[id]
+		cinema := onDay[idx[0]].Cinema
+		resp.Cinemas = append(resp.Cinemas, dto.CinemaShowtimes{
+			CinemaID: cinema.ID,
+			Name:     cinema.Name,
+			City:     cinema.City,
+			Address:  cinema.Address,
+			TimeZone: cinema.Location().String(),
+			Date:     slots[idx[0]].StartTime.Format(dateLayout),
+			Formats:  groupByFormat(onDay, slots, idx),
+		})
+	}
+	sort.SliceStable(resp.Cinemas, func(i, j int) bool { return resp.Cinemas[i].Name < resp.Cinemas[j].Name })
+	return resp, nil
+}
+
+// GetCinemaSchedule lists the movies playing at the cinema on date (today
+// when empty), using the cinema's local day boundaries.
+func (s *MovieService) GetCinemaSchedule(cinemaID int64, date string) (*dto.CinemaScheduleResponse, error) {
+	cinema, err := s.CinemaRepo.GetByID(cinemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := cinema.Location()
+	day := time.Now().In(loc)
+	if date != "" {
+		if day, err = time.ParseInLocation(dateLayout, date, loc); err != nil {
+			return nil, domain.ErrInvalidDate
+		}
+	}
+	from, to := cinema.LocalDay(day)
+
+	showtimes, err := s.Repo.GetUpcomingShowtimes(domain.ShowtimeFilter{CinemaID: cinemaID, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	slots, err := s.toSlots(showtimes)
+	if err != nil {
+		return nil, err
+	}
+
+	byMovie := make(map[int64][]int)
+	var movieIDs []int64
+	for i, st := range showtimes {
+		if _, ok := byMovie[st.MovieID]; !ok {
+			movieIDs = append(movieIDs, st.MovieID)
+		}
+		byMovie[st.MovieID] = append(byMovie[st.MovieID], i)
+	}
+
+	resp := &dto.CinemaScheduleResponse{
+		CinemaID: cinema.ID,
+		Name:     cinema.Name,
+		City:     cinema.City,
+		TimeZone: loc.String(),
+		Date:     day.Format(dateLayout),
+		Movies:   []dto.MovieSchedule{},
+	}
+	for _, id := range movieIDs {
+		idx := byMovie[id]
+		movie := showtimes[idx[0]].Movie
+		resp.Movies = append(resp.Movies, dto.MovieSchedule{
+			MovieID:          movie.ID,
+			Title:            movie.Title,
+			PosterURL:        movie.PosterURL,
+			Duration:         movie.Duration,
+			AgeCertification: movie.AgeCertification,
+			Formats:          groupByFormat(showtimes, slots, idx),
+		})
+	}
+	sort.SliceStable(resp.Movies, func(i, j int) bool { return resp.Movies[i].Title < resp.Movies[j].Title })
+	return resp, nil
+}
+
+// toSlots turns showtimes into listing entries with their remaining seats and
+// price-from, in the same order.
+func (s *MovieService) toSlots(showtimes []domain.Showtime) ([]dto.ShowtimeSlot, error) {
+	showtimeIDs := make([]int64, len(showtimes))
+	var theaterIDs []int64
+	for i, st := range showtimes {
+		showtimeIDs[i] = st.ID
+		if st.TheaterID != nil {
+			theaterIDs = append(theaterIDs, *st.TheaterID)
+		}
+	}
+
+	taken, err := s.Repo.CountTakenSeats(showtimeIDs)
+	if err != nil {
+		return nil, err
+	}
+	capacity, err := s.Repo.CountBookableSeats(theaterIDs)
+	if err != nil {
+		return nil, err
+	}
+	pricer, err := s.pricerFor(showtimes)
+	if err != nil {
+		return nil, err
+	}
+	// Theaters without their own seat map use the default layout
+	defaultCapacity := len(cinemaDomain.DefaultSeatMap(0))
+
+	slots := make([]dto.ShowtimeSlot, len(showtimes))
+	for i, st := range showtimes {
+		seats := defaultCapacity
+		slot := dto.ShowtimeSlot{ID: st.ID, PriceFrom: priceFrom(pricer, st)}
+		if st.Theater != nil {
+			slot.TheaterID = st.Theater.ID
+			slot.TheaterName = st.Theater.Name
+			if n, ok := capacity[st.Theater.ID]; ok {
+				seats = n
+			}
+		}
+		slot.StartTime = st.StartTime.In(st.Cinema.Location())
+		slot.Time = slot.StartTime.Format("15:04")
+		slot.RemainingSeats = max(seats-taken[st.ID], 0)
+		slots[i] = slot
+	}
+	return slots, nil
+}
+
+// groupByFormat groups the showtimes at idx by their theater's type, formats
+// in alphabetical order and showtimes in the order given.
+func groupByFormat(showtimes []domain.Showtime, slots []dto.ShowtimeSlot, idx []int) []dto.FormatShowtimes {
+	var formats []dto.FormatShowtimes
+	position := make(map[string]int)
+	for _, i := range idx {
+		format := "Regular"
+		if t := showtimes[i].Theater; t != nil && t.Type != "" {
+			format = t.Type
+		}
+		p, ok := position[format]
+		if !ok {
+			p = len(formats)
+			position[format] = p
+			formats = append(formats, dto.FormatShowtimes{Format: format})
+		}
+		formats[p].Showtimes = append(formats[p].Showtimes, slots[i])
+	}
+	sort.SliceStable(formats, func(i, j int) bool { return formats[i].Format < formats[j].Format })
+	return formats
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+)
+
+const (
+	maxPosterBytes     = 5 << 20
+	maxPosterRedirects = 3
+)
+
+var errPrivateAddress = errors.New("address is not public")
+
+// cgnat is the shared address space of carrier-grade NAT (RFC 6598), which
+// IsPrivate leaves out.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// posterClient downloads posters from the public internet only: every
+// connection, including those of redirects, is checked after DNS resolution,
+// so a poster URL cannot reach the server's own network.
+var posterClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: publicOnly,
+		}).DialContext,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxPosterRedirects {
+			return errors.New("too many redirects")
+		}
+		return checkPosterURL(req.URL)
+	},
+}
+
+// publicOnly refuses connections to loopback, private, CGNAT, link-local and
+// other non-public addresses.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() || cgnat.Contains(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+func checkPosterURL(u *url.URL) error {
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return domain.ErrInvalidPoster
+	}
+	return nil
+}
+
+// validPosterURL checks a poster URL before it is stored; an empty one
+// removes the poster. The image itself is fetched later by FetchPosters.
+func validPosterURL(rawURL string) error {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return domain.ErrInvalidPoster
+	}
+	return checkPosterURL(u)
+}
+
+// FetchPosters is a scheduler job: it downloads the posters of movies whose
+// poster URL was set or changed since their poster was stored. A poster host
+// that is down only delays the poster, which is tried again next run.
+func (s *MovieService) FetchPosters(now time.Time) (int64, error) {
+	movies, err := s.Repo.GetMoviesMissingPoster()
+	if err != nil {
+		return 0, err
+	}
+
+	var fetched int64
+	for _, movie := range movies {
+		data, imageType, err := fetchPoster(movie.PosterURL)
+		if err != nil {
+			log.Printf("Warning: Failed to fetch poster of movie %d: %v", movie.ID, err)
+			continue
+		}
+		poster := &domain.MoviePoster{MovieID: movie.ID, URL: movie.PosterURL, Image: data, ImageType: imageType, FetchedAt: now}
+		if err := s.Repo.SavePoster(poster); err != nil {
+			return fetched, err
+		}
+		fetched++
+	}
+	return fetched, nil
+}
+
+// fetchPoster downloads a JPEG or PNG image and returns it with its gofpdf
+// image type. The type comes from the image itself, not the Content-Type.
+func fetchPoster(rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := checkPosterURL(u); err != nil {
+		return nil, "", err
+	}
+
+	resp, err := posterClient.Get(u.String())
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("poster host answered %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPosterBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxPosterBytes {
+		return nil, "", errors.New("poster is larger than 5 MB")
+	}
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", errors.New("poster is not a JPEG or PNG image")
+	}
+	switch format {
+	case "jpeg":
+		return data, "JPG", nil
+	case "png":
+		return data, "PNG", nil
+	}
+	return nil, "", errors.New("poster is not a JPEG or PNG image")
+}
@@ -1,16 +1,28 @@
 package service
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
+	pricingDomain "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	pricingService "github.com/geraldiaditya/ratix-backend/internal/modules/pricing/service"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 )
 
 type MovieService struct {
-	Repo domain.MovieRepository
+	Repo       domain.MovieRepository
+	CinemaRepo cinemaDomain.CinemaRepository
+	Pricing    *pricingService.PricingService
+	// ShowtimeBuffer is the cleaning/ads time kept free after every showtime
+	ShowtimeBuffer time.Duration
 }
 
-func NewMovieService(repo domain.MovieRepository) *MovieService {
-	return &MovieService{Repo: repo}
+func NewMovieService(repo domain.MovieRepository, cinemaRepo cinemaDomain.CinemaRepository, pricing *pricingService.PricingService, showtimeBuffer time.Duration) *MovieService {
+	return &MovieService{Repo: repo, CinemaRepo: cinemaRepo, Pricing: pricing, ShowtimeBuffer: showtimeBuffer}
 }
 
 func (s *MovieService) GetCategories() ([]dto.GenreResponse, error) {
@@ -28,7 +40,7 @@ func (s *MovieService) GetCategories() ([]dto.GenreResponse, error) {
 func (s *MovieService) GetBanner() (*dto.BannerResponse, error) {
 	// Logic: Get 'now_showing' AND standard picking logic (e.g. highest rated or first)
 	// For banner, we might just want 1, so limit=1, offset=0
-	movies, _, err := s.Repo.GetByStatus("now_showing", 1, 0)
+	movies, _, err := s.Repo.List(domain.MovieFilter{Status: domain.StatusNowShowing}, 1, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -51,53 +63,166 @@ func (s *MovieService) GetBanner() (*dto.BannerResponse, error) {
 	}, nil
 }
 
-func (s *MovieService) GetMovies(category string, page, limit int) (*dto.MovieListResponse, error) {
+// GetMovies lists movies matching every filter of q. Without a status or
+// genre filter only movies now showing are listed; status "all" lifts that.
+func (s *MovieService) GetMovies(q dto.MovieListQuery) (*dto.MovieListResponse, error) {
+	page, limit := q.Page, pagination.Limit(q.Limit)
 	if page < 1 {
 		page = 1
 	}
-	if limit < 1 {
-		limit = 10
+
+	filter, err := movieFilter(q)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, total, err := s.Repo.List(filter, limit, (page-1)*limit)
+	if err != nil {
+		return nil, err
+	}
+	return toMovieList(movies, total, page, limit), nil
+}
+
+// GetMoviesByCursor is GetMovies paged by q.Cursor instead of q.Page, with
+// a stable order as movies are added.
+func (s *MovieService) GetMoviesByCursor(q dto.MovieListQuery) (*dto.MovieListResponse, error) {
+	filter, err := movieFilter(q)
+	if err != nil {
+		return nil, err
+	}
+
+	movies, meta, err := s.Repo.ListByCursor(filter, q.Cursor, pagination.Limit(q.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MovieListResponse{Movies: toMovieResponses(movies), Cursor: &meta}, nil
+}
+
+func movieFilter(q dto.MovieListQuery) (domain.MovieFilter, error) {
+	filter := domain.MovieFilter{
+		Status:         q.Status,
+		Genres:         splitList(q.Genres),
+		MinRating:      q.MinRating,
+		MaxDuration:    q.MaxDuration,
+		Certifications: splitList(q.Certification),
+		City:           strings.TrimSpace(q.City),
+		PlayingOn:      q.Date,
+		Sort:           q.Sort,
+	}
+
+	switch q.Category {
+	case "":
+	case domain.StatusNowShowing, domain.StatusComingSoon:
+		if filter.Status == "" {
+			filter.Status = q.Category
+		}
+	default:
+		filter.Genres = append(filter.Genres, q.Category)
+	}
+	if filter.Status == "" && len(filter.Genres) == 0 {
+		filter.Status = domain.StatusNowShowing
+	}
+	if filter.Status == "all" {
+		filter.Status = ""
+	}
+
+	for i, c := range filter.Certifications {
+		// An unescaped "+" in a query string arrives as a space, trimmed away
+		if c != domain.CertificationAllAges && !strings.HasSuffix(c, "+") {
+			c += "+"
+			filter.Certifications[i] = c
+		}
+		switch c {
+		case domain.CertificationAllAges, domain.Certification13Plus, domain.Certification17Plus, domain.Certification21Plus:
+		default:
+			return filter, fmt.Errorf("%w: unknown certification %q", domain.ErrInvalidMovieFilter, c)
+		}
 	}
-	offset := (page - 1) * limit
 
-	var movies []domain.Movie
-	var total int64
 	var err error
+	if filter.ReleasedFrom, err = parseDay(q.ReleasedFrom); err != nil {
+		return filter, err
+	}
+	if filter.ReleasedTo, err = parseDay(q.ReleasedTo); err != nil {
+		return filter, err
+	}
+	if filter.ReleasedFrom != nil && filter.ReleasedTo != nil && filter.ReleasedTo.Before(*filter.ReleasedFrom) {
+		return filter, fmt.Errorf("%w: released_to is before released_from", domain.ErrInvalidMovieFilter)
+	}
 
-	if category == "" || category == "now_showing" || category == "coming_soon" {
-		status := category
-		if status == "" {
-			status = "now_showing" // Default
+	// Best first unless asked otherwise; titles read A to Z
+	filter.Descending = filter.Sort != "" && filter.Sort != domain.SortTitle
+	if q.Order != "" {
+		filter.Descending = q.Order == "desc"
+	}
+	return filter, nil
+}
+
+// parseDay parses an optional YYYY-MM-DD date; empty gives nil.
+func parseDay(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	day, err := time.Parse(dateLayout, v)
+	if err != nil {
+		return nil, domain.ErrInvalidDate
+	}
+	return &day, nil
+}
+
+// splitList splits a comma-separated query value, dropping empty items.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
 		}
-		movies, total, err = s.Repo.GetByStatus(status, limit, offset)
-	} else {
-		// Assume it's a genre
-		movies, total, err = s.Repo.GetByGenre(category, limit, offset)
 	}
+	return items
+}
+
+// SearchMovies finds movies by title, description, genre or cast, tolerating
+// typos, most relevant first.
+func (s *MovieService) SearchMovies(q string, page, limit int) (*dto.MovieListResponse, error) {
+	terms := domain.SearchTerms(q)
+	if len(terms) == 0 {
+		return nil, domain.ErrEmptySearchQuery
+	}
+	if page < 1 {
+		page = 1
+	}
+	limit = pagination.Limit(limit)
 
+	movies, total, err := s.Repo.Search(terms, limit, (page-1)*limit)
 	if err != nil {
 		return nil, err
 	}
+	return toMovieList(movies, total, page, limit), nil
+}
 
+func toMovieResponses(movies []domain.Movie) []dto.MovieResponse {
 	resp := make([]dto.MovieResponse, len(movies))
 	for i, m := range movies {
 		resp[i] = dto.ToMovieResponse(m)
 	}
+	return resp
+}
 
+func toMovieList(movies []domain.Movie, total int64, page, limit int) *dto.MovieListResponse {
 	totalPages := int(total) / limit
 	if int(total)%limit != 0 {
 		totalPages++
 	}
 
 	return &dto.MovieListResponse{
-		Movies: resp,
-		Meta: dto.PaginationMeta{
+		Movies: toMovieResponses(movies),
+		Meta: &dto.PaginationMeta{
 			CurrentPage: page,
 			TotalPages:  totalPages,
 			TotalItems:  total,
 			Limit:       limit,
 		},
-	}, nil
+	}
 }
 
 func (s *MovieService) GetDetail(id int64) (*dto.MovieDetailResponse, error) {
@@ -105,5 +230,70 @@ func (s *MovieService) GetDetail(id int64) (*dto.MovieDetailResponse, error) {
 	if err != nil {
 		return nil, err
 	}
-	return dto.ToMovieDetailResponse(movie), nil
+
+	resp := dto.ToMovieDetailResponse(movie)
+	pricer, err := s.pricerFor(movie.Showtimes)
+	if err != nil {
+		return nil, err
+	}
+	for i, st := range movie.Showtimes {
+		resp.Showtimes[i].Price = priceFrom(pricer, st)
+	}
+	return resp, nil
+}
+
+// pricerFor loads the pricing of the showtimes' dates once, so each one is
+// priced without further queries. Nil when there are no showtimes.
+func (s *MovieService) pricerFor(showtimes []domain.Showtime) (*pricingService.Pricer, error) {
+	if len(showtimes) == 0 {
+		return nil, nil
+	}
+	first := showtimes[0].StartTime.In(showtimes[0].Cinema.Location())
+	last := first
+	for _, st := range showtimes[1:] {
+		local := st.StartTime.In(st.Cinema.Location())
+		// Compared as dates: cinemas may be in different zones
+		if local.Format("2006-01-02") < first.Format("2006-01-02") {
+			first = local
+		}
+		if local.Format("2006-01-02") > last.Format("2006-01-02") {
+			last = local
+		}
+	}
+	return s.Pricing.NewPricer(first, last)
+}
+
+// priceFrom is the "from" price of a showtime: a standard seat, priced by the
+// same rules as the seat layout and bookings.
+func priceFrom(pricer *pricingService.Pricer, st domain.Showtime) float64 {
+	theaterType := "Regular"
+	if st.Theater != nil {
+		theaterType = st.Theater.Type
+	}
+	return pricer.Quote(pricingDomain.QuoteInput{
+		BasePrice:   st.Cinema.BasePrice,
+		SeatType:    cinemaDomain.SeatTypeStandard,
+		TheaterType: theaterType,
+		MovieID:     st.MovieID,
+		StartTime:   st.StartTime.In(st.Cinema.Location()),
+	}).Price
+}
+
+// ReleaseDueMovies is a scheduler job: coming_soon movies go to now_showing on
+// their release date. A movie is out once that date has begun at any of the
+// chain's cinemas, so the day is taken in the zone furthest ahead.
+func (s *MovieService) ReleaseDueMovies(now time.Time) (int64, error) {
+	cinemas, err := s.CinemaRepo.GetCinemasByCity("")
+	if err != nil {
+		return 0, err
+	}
+	loc, _ := cinemaDomain.LoadTimeZone(cinemaDomain.DefaultTimeZone)
+	today := now.In(loc)
+	for i, cinema := range cinemas {
+		local := now.In(cinema.Location())
+		if i == 0 || local.Format("2006-01-02") > today.Format("2006-01-02") {
+			today = local
+		}
+	}
+	return s.Repo.ReleaseDue(today)
 }
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/movie/dto"
+)
+
+// maxBulkScheduleDays caps how far a single bulk request may reach.
+const maxBulkScheduleDays = 62
+
+func (s *MovieService) ScheduleShowtime(req dto.ScheduleShowtimeRequest) ([]dto.ScheduledShowtimeResponse, error) {
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_time must be RFC3339", domain.ErrInvalidSchedule)
+	}
+	theater, err := s.CinemaRepo.GetTheaterByID(req.TheaterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.schedule(req.MovieID, theater, []time.Time{start})
+}
+
+// BulkScheduleShowtimes expands a "every day at 13:00, 16:00, 19:00" request
+// into individual showtimes and schedules them all or none. Dates and times
+// are on the cinema's local clock.
+func (s *MovieService) BulkScheduleShowtimes(req dto.BulkScheduleRequest) ([]dto.ScheduledShowtimeResponse, error) {
+	theater, err := s.CinemaRepo.GetTheaterByID(req.TheaterID)
+	if err != nil {
+		return nil, err
+	}
+	loc := theater.Cinema.Location()
+
+	from, err := time.ParseInLocation("2006-01-02", req.StartDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid start_date", domain.ErrInvalidSchedule)
+	}
+	to, err := time.ParseInLocation("2006-01-02", req.EndDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid end_date", domain.ErrInvalidSchedule)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: end_date is before start_date", domain.ErrInvalidSchedule)
+	}
+	if to.Sub(from) >= maxBulkScheduleDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: at most %d days per request", domain.ErrInvalidSchedule, maxBulkScheduleDays)
+	}
+
+	var starts []time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, t := range req.Times {
+			clock, err := time.Parse("15:04", t)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid time %q", domain.ErrInvalidSchedule, t)
+			}
+			starts = append(starts, time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location()))
+		}
+	}
+	return s.schedule(req.MovieID, theater, starts)
+}
+
+func (s *MovieService) schedule(movieID int64, theater *cinemaDomain.Theater, starts []time.Time) ([]dto.ScheduledShowtimeResponse, error) {
+	movie, err := s.Repo.GetByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	if movie.Status == domain.StatusArchived {
+		return nil, fmt.Errorf("%w: movie is archived", domain.ErrInvalidSchedule)
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	now := time.Now()
+	duration := time.Duration(movie.Duration) * time.Minute
+
+	// The request must not overlap itself before we look at the database
+	var conflicts []domain.ShowtimeConflict
+	for i, start := range starts {
+		if !start.After(now) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrShowtimeInPast, start.Format(time.RFC3339))
+		}
+		if i > 0 && start.Before(starts[i-1].Add(duration+s.ShowtimeBuffer)) {
+			conflicts = append(conflicts, domain.ShowtimeConflict{StartTime: start, ConflictingStartTime: starts[i-1]})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, &domain.ShowtimeConflictError{Conflicts: conflicts}
+	}
+
+	showtimes := make([]domain.Showtime, len(starts))
+	for i, start := range starts {
+		showtimes[i] = domain.Showtime{
+			MovieID:   movieID,
+			CinemaID:  theater.CinemaID,
+			TheaterID: &theater.ID,
+			StartTime: start.UTC(),
+		}
+	}
+	if err := s.Repo.CreateShowtimes(theater.ID, showtimes, duration, s.ShowtimeBuffer); err != nil {
+		return nil, err
+	}
+
+	loc := theater.Cinema.Location()
+	resp := make([]dto.ScheduledShowtimeResponse, len(showtimes))
+	for i, st := range showtimes {
+		resp[i] = dto.ScheduledShowtimeResponse{
+			ID:        st.ID,
+			MovieID:   st.MovieID,
+			CinemaID:  st.CinemaID,
+			TheaterID: theater.ID,
+			StartTime: st.StartTime.In(loc),
+			EndTime:   st.StartTime.Add(duration).In(loc),
+		}
+	}
+	return resp, nil
+}
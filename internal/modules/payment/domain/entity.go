@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrUnknownProvider     = errors.New("unknown payment provider")
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+	ErrNotRefundable       = errors.New("payment is not captured")
+	ErrRefundExceedsAmount = errors.New("refund exceeds captured amount")
+	ErrRefundInProgress    = errors.New("a refund of the payment is already in progress")
+)
+
+// Payment statuses. A payment moves strictly forward:
+//
+//	pending -> authorized -> captured -> refunding -> refunded
+//	pending/authorized -> failed -> refund_due -> refunding -> refunded
+//
+// Providers may capture without a separate authorization step. A payment is
+// refunding while its refund is with the provider, and goes back to captured
+// if the provider turns the refund down. A payment the provider captures after
+// it failed has lost its seats, so the whole amount is due back to the
+// customer until the refund goes through.
+const (
+	StatusPending    = "pending"
+	StatusAuthorized = "authorized"
+	StatusCaptured   = "captured"
+	StatusFailed     = "failed"
+	StatusRefundDue  = "refund_due"
+	StatusRefunding  = "refunding"
+	StatusRefunded   = "refunded"
+)
+
+var transitions = map[string][]string{
+	StatusPending:    {StatusAuthorized, StatusCaptured, StatusFailed},
+	StatusAuthorized: {StatusCaptured, StatusFailed},
+	StatusFailed:     {StatusRefundDue},
+	StatusCaptured:   {StatusRefunding},
+	StatusRefundDue:  {StatusRefunding},
+	StatusRefunding:  {StatusRefunded, StatusCaptured, StatusRefundDue},
+}
+
+// CanTransition reports whether a payment may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Payment is one attempt to pay for a ticket.
+type Payment struct {
+	ID             int64     `gorm:"primaryKey" json:"id"`
+	TicketID       int64     `gorm:"not null;index" json:"ticket_id"`
+	UserID         int64     `gorm:"not null;index" json:"user_id"`
+	Provider       string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_payments_provider_ref" json:"provider"`
+	ProviderRef    *string   `gorm:"type:varchar(100);uniqueIndex:idx_payments_provider_ref" json:"provider_ref"` // Charge ID at the provider
+	CheckoutURL    string    `gorm:"type:varchar(255)" json:"checkout_url"`
+	Amount         float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	RefundedAmount float64   `gorm:"type:decimal(10,2);not null;default:0" json:"refunded_amount"`
+	Currency       string    `gorm:"type:varchar(3);not null" json:"currency"`
+	Status         string    `gorm:"type:varchar(20);not null" json:"status"`
+	FailureReason  string    `gorm:"type:varchar(255)" json:"failure_reason"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// CapturedAt is when the money was taken; unlike UpdatedAt, a refund
+	// leaves it alone
+	CapturedAt *time.Time `json:"captured_at"`
+}
+
+// ChargeRequest asks a provider to start collecting Amount.
+type ChargeRequest struct {
+	PaymentID   int64
+	Amount      float64
+	Currency    string
+	Description string
+}
+
+// Charge is the provider's answer to a ChargeRequest. The customer completes
+// the payment at CheckoutURL; the result arrives through the webhook.
+type Charge struct {
+	ProviderRef string
+	CheckoutURL string
+}
+
+// Webhook event types, normalized across providers.
+const (
+	EventAuthorized = "charge.authorized"
+	EventCaptured   = "charge.captured"
+	EventFailed     = "charge.failed"
+)
+
+type WebhookEvent struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	ProviderRef string `json:"charge_id"`
+	Reason      string `json:"reason,omitempty"` // Set on failures
+}
+
+// PaymentGateway is implemented once per payment provider.
+type PaymentGateway interface {
+	Name() string
+	CreateCharge(req ChargeRequest) (*Charge, error)
+	Capture(providerRef string, amount float64) error
+	// Void releases an authorization without taking the money.
+	Void(providerRef string) error
+	Refund(providerRef string, amount float64) error
+	// VerifyWebhook checks the signature of a raw webhook body and decodes it.
+	// Returns ErrInvalidSignature when the body was not sent by the provider.
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+type PaymentRepository interface {
+	Create(payment *Payment) error
+	GetByID(id int64) (*Payment, error)
+	GetLatestByTicketID(ticketID int64) (*Payment, error)
+	GetByProviderRef(provider, ref string) (*Payment, error)
+	GetPendingBefore(before time.Time) ([]Payment, error)
+	// GetAuthorized returns the payments authorized at their provider but not
+	// captured yet.
+	GetAuthorized() ([]Payment, error)
+	// GetRefundsDue returns the payments captured after they failed whose
+	// refund has not gone through yet.
+	GetRefundsDue() ([]Payment, error)
+	SetCharge(id int64, charge *Charge) error
+	// UpdateStatus moves the payment only if it is still in status from, and
+	// reports whether it did.
+	UpdateStatus(id int64, from, to string) (bool, error)
+	// MarkCaptured captures the payment and activates its pending ticket.
+	MarkCaptured(id int64, from string) (bool, error)
+	// MarkFailed fails the payment, marks its pending ticket as failed and
+	// releases the ticket's seats.
+	MarkFailed(id int64, from, reason string) (bool, error)
+	// MarkCapturedLate records that a failed payment was captured anyway and
+	// moves it to refund_due for its whole amount. Its ticket is left failed.
+	MarkCapturedLate(id int64) (bool, error)
+	// ClaimRefund moves a captured payment to refunding with the amount about
+	// to be refunded, under a row lock, and reports whether it did. Only the
+	// caller that claimed the payment may ask the provider for the refund.
+	ClaimRefund(id int64, amount float64) (bool, error)
+	// ReleaseRefund puts a refunding payment back to captured after the
+	// provider turned the refund down.
+	ReleaseRefund(id int64) error
+	// MarkRefunded completes the refund of a refunding payment.
+	MarkRefunded(id int64) (bool, error)
+}
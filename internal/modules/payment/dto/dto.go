@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+)
+
+type PaymentResponse struct {
+	ID             int64   `json:"id"`
+	TicketID       int64   `json:"ticket_id"`
+	Provider       string  `json:"provider"`
+	Status         string  `json:"status"`
+	Amount         float64 `json:"amount"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	Currency       string  `json:"currency"`
+	CheckoutURL    string  `json:"checkout_url,omitempty"`
+	FailureReason  string  `json:"failure_reason,omitempty"`
+}
+
+// FakeCheckoutRequest decides the outcome of a payment with the fake provider.
+type FakeCheckoutRequest struct {
+	Outcome string `json:"outcome" validate:"required,oneof=success decline"`
+}
+
+func ToPaymentResponse(p domain.Payment) PaymentResponse {
+	resp := PaymentResponse{
+		ID:             p.ID,
+		TicketID:       p.TicketID,
+		Provider:       p.Provider,
+		Status:         p.Status,
+		Amount:         p.Amount,
+		RefundedAmount: p.RefundedAmount,
+		Currency:       p.Currency,
+		FailureReason:  p.FailureReason,
+	}
+	// Only worth following while the payment is still open
+	if p.Status == domain.StatusPending {
+		resp.CheckoutURL = p.CheckoutURL
+	}
+	return resp
+}
@@ -0,0 +1,76 @@
+// Package gateway holds the PaymentGateway implementations.
+package gateway
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+)
+
+// FakeName is the provider name of FakeGateway, as used in webhook URLs.
+const FakeName = "fake"
+
+// FakeGateway is an in-process provider for development and tests. Charges
+// are never sent anywhere: charge IDs derive from the payment ID, captures,
+// voids and refunds always succeed, and the outcome is decided by whoever "pays" at the
+// checkout URL, which sends a webhook signed like a real provider would.
+type FakeGateway struct {
+	Secret []byte
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{Secret: []byte(secret)}
+}
+
+func (g *FakeGateway) Name() string {
+	return FakeName
+}
+
+func (g *FakeGateway) CreateCharge(req domain.ChargeRequest) (*domain.Charge, error) {
+	ref := fmt.Sprintf("fake_ch_%d", req.PaymentID)
+	return &domain.Charge{
+		ProviderRef: ref,
+		CheckoutURL: "/payments/fake/checkout/" + ref,
+	}, nil
+}
+
+func (g *FakeGateway) Capture(providerRef string, amount float64) error {
+	return nil
+}
+
+func (g *FakeGateway) Void(providerRef string) error {
+	return nil
+}
+
+func (g *FakeGateway) Refund(providerRef string, amount float64) error {
+	return nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (*domain.WebhookEvent, error) {
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.mac(payload)) {
+		return nil, domain.ErrInvalidSignature
+	}
+
+	var event domain.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	return &event, nil
+}
+
+// Sign returns the signature FakeGateway expects for a webhook body, hex
+// encoded HMAC-SHA256 with the webhook secret.
+func (g *FakeGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(g.mac(payload))
+}
+
+func (g *FakeGateway) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, g.Secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/gateway"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SignatureHeader carries the provider's signature of the raw webhook body.
+const SignatureHeader = "X-Signature"
+
+type PaymentHandler struct {
+	Service   *service.PaymentService
+	Validator *validator.Validate
+}
+
+func NewPaymentHandler(service *service.PaymentService, v *validator.Validate) *PaymentHandler {
+	return &PaymentHandler{Service: service, Validator: v}
+}
+
+func (h *PaymentHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	payments := app.Group("/payments")
+	// Called by the provider, authenticated by signature instead of JWT
+	payments.Post("/webhook/:provider", h.handleWebhook)
+	payments.Get("/:id", auth, h.handleGetPayment)
+
+	if _, ok := h.Service.Gateways[gateway.FakeName]; ok {
+		payments.Post("/fake/checkout/:ref", auth, h.handleFakeCheckout)
+	}
+}
+
+func (h *PaymentHandler) handleWebhook(c *fiber.Ctx) error {
+	resp, err := h.Service.HandleWebhook(c.Params("provider"), c.Body(), c.Get(SignatureHeader))
+	if err != nil {
+		return c.Status(paymentErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PaymentHandler) handleGetPayment(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetPayment(id, middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(paymentErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PaymentHandler) handleFakeCheckout(c *fiber.Ctx) error {
+	var req dto.FakeCheckoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.FakeCheckout(c.Params("ref"), middleware.GetUserID(c), req.Outcome)
+	if err != nil {
+		return c.Status(paymentErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrPaymentNotFound), errors.Is(err, domain.ErrUnknownProvider):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSignature):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, domain.ErrNotRefundable), errors.Is(err, domain.ErrRefundExceedsAmount),
+		errors.Is(err, domain.ErrRefundInProgress):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
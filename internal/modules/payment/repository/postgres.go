@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresPaymentRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresPaymentRepository(db *gorm.DB) *PostgresPaymentRepository {
+	return &PostgresPaymentRepository{DB: db}
+}
+
+func (r *PostgresPaymentRepository) Create(payment *domain.Payment) error {
+	return r.DB.Create(payment).Error
+}
+
+func (r *PostgresPaymentRepository) GetByID(id int64) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.DB.First(&payment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PostgresPaymentRepository) GetLatestByTicketID(ticketID int64) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.DB.Where("ticket_id = ?", ticketID).Order("id desc").First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PostgresPaymentRepository) GetByProviderRef(provider, ref string) (*domain.Payment, error) {
+	var payment domain.Payment
+	if err := r.DB.Where("provider = ? AND provider_ref = ?", provider, ref).First(&payment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *PostgresPaymentRepository) GetPendingBefore(before time.Time) ([]domain.Payment, error) {
+	var payments []domain.Payment
+	if err := r.DB.Where("status = ? AND created_at < ?", domain.StatusPending, before).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) GetAuthorized() ([]domain.Payment, error) {
+	var payments []domain.Payment
+	if err := r.DB.Where("status = ? AND provider_ref IS NOT NULL", domain.StatusAuthorized).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) GetRefundsDue() ([]domain.Payment, error) {
+	var payments []domain.Payment
+	if err := r.DB.Where("status = ?", domain.StatusRefundDue).Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *PostgresPaymentRepository) SetCharge(id int64, charge *domain.Charge) error {
+	return r.DB.Model(&domain.Payment{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"provider_ref": charge.ProviderRef, "checkout_url": charge.CheckoutURL}).Error
+}
+
+func (r *PostgresPaymentRepository) UpdateStatus(id int64, from, to string) (bool, error) {
+	result := r.DB.Model(&domain.Payment{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// Tickets live in the ticket module, which depends on this one, so they are
+// addressed by table name.
+
+func (r *PostgresPaymentRepository) MarkCaptured(id int64, from string) (bool, error) {
+	var moved bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var payment domain.Payment
+		result := tx.Model(&payment).
+			Where("id = ? AND status = ?", id, from).
+			Updates(map[string]interface{}{"status": domain.StatusCaptured, "captured_at": time.Now()})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		moved = true
+
+		if err := tx.First(&payment, id).Error; err != nil {
+			return err
+		}
+		return tx.Table("tickets").
+			Where("id = ? AND status = ?", payment.TicketID, "pending_payment").
+			Update("status", "active").Error
+	})
+	return moved, err
+}
+
+func (r *PostgresPaymentRepository) MarkFailed(id int64, from, reason string) (bool, error) {
+	var moved bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var payment domain.Payment
+		result := tx.Model(&payment).
+			Where("id = ? AND status = ?", id, from).
+			Updates(map[string]interface{}{"status": domain.StatusFailed, "failure_reason": reason})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		moved = true
+
+		if err := tx.First(&payment, id).Error; err != nil {
+			return err
+		}
+		result = tx.Table("tickets").
+			Where("id = ? AND status = ?", payment.TicketID, "pending_payment").
+			Update("status", "payment_failed")
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		// Unpaid seats go back on sale
+		return tx.Table("ticket_seats").
+			Where("ticket_id = ?", payment.TicketID).
+			Update("cancelled", true).Error
+	})
+	return moved, err
+}
+
+func (r *PostgresPaymentRepository) MarkCapturedLate(id int64) (bool, error) {
+	result := r.DB.Model(&domain.Payment{}).
+		Where("id = ? AND status = ?", id, domain.StatusFailed).
+		Updates(map[string]interface{}{
+			"status":          domain.StatusRefundDue,
+			"captured_at":     time.Now(),
+			"refunded_amount": gorm.Expr("amount"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *PostgresPaymentRepository) ClaimRefund(id int64, amount float64) (bool, error) {
+	var claimed bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var payment domain.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrPaymentNotFound
+			}
+			return err
+		}
+		if payment.Status != domain.StatusCaptured {
+			return nil
+		}
+		claimed = true
+		return tx.Model(&payment).
+			Updates(map[string]interface{}{"status": domain.StatusRefunding, "refunded_amount": amount}).Error
+	})
+	return claimed, err
+}
+
+func (r *PostgresPaymentRepository) ReleaseRefund(id int64) error {
+	return r.DB.Model(&domain.Payment{}).
+		Where("id = ? AND status = ?", id, domain.StatusRefunding).
+		Updates(map[string]interface{}{"status": domain.StatusCaptured, "refunded_amount": 0}).Error
+}
+
+func (r *PostgresPaymentRepository) MarkRefunded(id int64) (bool, error) {
+	result := r.DB.Model(&domain.Payment{}).
+		Where("id = ? AND status = ?", id, domain.StatusRefunding).
+		Update("status", domain.StatusRefunded)
+	return result.RowsAffected > 0, result.Error
+}
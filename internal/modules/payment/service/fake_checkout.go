@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/gateway"
+)
+
+// FakeCheckout plays the customer at the fake provider's checkout page: it
+// builds the webhook the provider would send for the chosen outcome, signs it
+// and feeds it through HandleWebhook, exactly like a real delivery.
+func (s *PaymentService) FakeCheckout(ref string, userID int64, outcome string) (*dto.PaymentResponse, error) {
+	fake, ok := s.Gateways[gateway.FakeName].(*gateway.FakeGateway)
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+
+	payment, err := s.Repo.GetByProviderRef(gateway.FakeName, ref)
+	if err != nil {
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	event := domain.WebhookEvent{ProviderRef: ref, Type: domain.EventAuthorized}
+	if outcome == "decline" {
+		event.Type = domain.EventFailed
+		event.Reason = "card declined"
+	}
+	event.ID = ref + ":" + event.Type
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return s.HandleWebhook(gateway.FakeName, payload, fake.Sign(payload))
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/payment/dto"
+)
+
+type PaymentService struct {
+	Repo domain.PaymentRepository
+	// Gateways by provider name; Provider is the one new payments go through
+	Gateways map[string]domain.PaymentGateway
+	Provider string
+	Currency string
+	// Timeout is how long a booking may wait for payment before it expires
+	Timeout time.Duration
+}
+
+func NewPaymentService(repo domain.PaymentRepository, gateway domain.PaymentGateway, currency string, timeout time.Duration) *PaymentService {
+	return &PaymentService{
+		Repo:     repo,
+		Gateways: map[string]domain.PaymentGateway{gateway.Name(): gateway},
+		Provider: gateway.Name(),
+		Currency: currency,
+		Timeout:  timeout,
+	}
+}
+
+// StartPayment opens a payment for a ticket awaiting payment and returns
+// where the customer can complete it. If the provider cannot be reached the
+// payment fails right away, which also releases the ticket's seats.
+func (s *PaymentService) StartPayment(ticketID, userID int64, amount float64, description string) (*dto.PaymentResponse, error) {
+	payment := &domain.Payment{
+		TicketID: ticketID,
+		UserID:   userID,
+		Provider: s.Provider,
+		Amount:   amount,
+		Currency: s.Currency,
+		Status:   domain.StatusPending,
+	}
+	if err := s.Repo.Create(payment); err != nil {
+		return nil, err
+	}
+
+	charge, err := s.Gateways[s.Provider].CreateCharge(domain.ChargeRequest{
+		PaymentID:   payment.ID,
+		Amount:      amount,
+		Currency:    s.Currency,
+		Description: description,
+	})
+	if err == nil {
+		err = s.Repo.SetCharge(payment.ID, charge)
+	}
+	if err != nil {
+		if _, failErr := s.Repo.MarkFailed(payment.ID, domain.StatusPending, "could not create charge"); failErr != nil {
+			return nil, failErr
+		}
+		return nil, fmt.Errorf("failed to create charge: %w", err)
+	}
+
+	ref := charge.ProviderRef
+	payment.ProviderRef = &ref
+	payment.CheckoutURL = charge.CheckoutURL
+	resp := dto.ToPaymentResponse(*payment)
+	return &resp, nil
+}
+
+// GetPayment returns the payment only if it belongs to userID.
+func (s *PaymentService) GetPayment(id, userID int64) (*dto.PaymentResponse, error) {
+	payment, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.UserID != userID {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	resp := dto.ToPaymentResponse(*payment)
+	return &resp, nil
+}
+
+// HandleWebhook applies a provider's webhook to its payment. Providers retry
+// webhooks, so events that were already applied, or that arrive after the
+// payment moved past them, are accepted without changing anything.
+func (s *PaymentService) HandleWebhook(provider string, payload []byte, signature string) (*dto.PaymentResponse, error) {
+	gateway, ok := s.Gateways[provider]
+	if !ok {
+		return nil, domain.ErrUnknownProvider
+	}
+	event, err := gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err := s.Repo.GetByProviderRef(provider, event.ProviderRef)
+	if err != nil {
+		return nil, err
+	}
+
+	switch event.Type {
+	case domain.EventAuthorized:
+		if payment.Status == domain.StatusPending {
+			if _, err := s.Repo.UpdateStatus(payment.ID, domain.StatusPending, domain.StatusAuthorized); err != nil {
+				return nil, err
+			}
+			payment.Status = domain.StatusAuthorized
+		}
+		// Seats are only reserved, so money is captured straight away. A
+		// failed capture is retried by ExpireStalePayments
+		if payment.Status == domain.StatusAuthorized {
+			if err := s.capture(gateway, payment); err != nil {
+				return nil, err
+			}
+		}
+	case domain.EventCaptured:
+		if domain.CanTransition(payment.Status, domain.StatusCaptured) {
+			if _, err := s.Repo.MarkCaptured(payment.ID, payment.Status); err != nil {
+				return nil, err
+			}
+		}
+		// The money was taken after the booking expired and its seats were
+		// released, so it all goes back
+		if payment.Status == domain.StatusFailed {
+			moved, err := s.Repo.MarkCapturedLate(payment.ID)
+			if err != nil {
+				return nil, err
+			}
+			if moved {
+				log.Printf("Warning: Payment %d was captured after it failed, refunding %.2f %s", payment.ID, payment.Amount, payment.Currency)
+				// A failed refund is retried by ExpireStalePayments
+				if err := s.refundDue(gateway, payment); err != nil {
+					log.Printf("Warning: Failed to refund payment %d: %v", payment.ID, err)
+				}
+			}
+		}
+	case domain.EventFailed:
+		if domain.CanTransition(payment.Status, domain.StatusFailed) {
+			if _, err := s.Repo.MarkFailed(payment.ID, payment.Status, event.Reason); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Reload: a concurrent delivery may have moved the payment meanwhile
+	payment, err = s.Repo.GetByID(payment.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToPaymentResponse(*payment)
+	return &resp, nil
+}
+
+// GetTicketPayment returns the ticket's most recent payment. Callers check
+// ticket ownership themselves.
+func (s *PaymentService) GetTicketPayment(ticketID int64) (*domain.Payment, error) {
+	return s.Repo.GetLatestByTicketID(ticketID)
+}
+
+// RefundTicket refunds amount of the ticket's captured payment and returns
+// the amount refunded. The payment is claimed before the provider is asked,
+// so concurrent requests cannot refund twice. Asking again after a refund
+// went through returns the amount refunded then, whatever amount is asked.
+func (s *PaymentService) RefundTicket(ticketID int64, amount float64) (float64, error) {
+	payment, err := s.Repo.GetLatestByTicketID(ticketID)
+	if err != nil {
+		return 0, err
+	}
+	switch payment.Status {
+	case domain.StatusRefunded:
+		return payment.RefundedAmount, nil
+	case domain.StatusRefunding:
+		return 0, domain.ErrRefundInProgress
+	}
+	if payment.Status != domain.StatusCaptured || payment.ProviderRef == nil {
+		return 0, domain.ErrNotRefundable
+	}
+	if amount > payment.Amount {
+		return 0, domain.ErrRefundExceedsAmount
+	}
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	gateway, ok := s.Gateways[payment.Provider]
+	if !ok {
+		return 0, domain.ErrUnknownProvider
+	}
+	claimed, err := s.Repo.ClaimRefund(payment.ID, amount)
+	if err != nil {
+		return 0, err
+	}
+	if !claimed {
+		return 0, domain.ErrRefundInProgress
+	}
+
+	if err := gateway.Refund(*payment.ProviderRef, amount); err != nil {
+		if releaseErr := s.Repo.ReleaseRefund(payment.ID); releaseErr != nil {
+			return 0, releaseErr
+		}
+		return 0, fmt.Errorf("failed to refund payment: %w", err)
+	}
+	if _, err := s.Repo.MarkRefunded(payment.ID); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+// ExpireStalePayments is a scheduler job: payments still pending after Timeout
+// fail, which releases the seats of their bookings. Authorized payments whose
+// capture failed are captured again, and once Timeout has passed they are
+// voided at their provider and fail as well. Refunds of payments captured
+// after they failed are retried.
+func (s *PaymentService) ExpireStalePayments(now time.Time) (int64, error) {
+	deadline := now.Add(-s.Timeout)
+
+	due, err := s.Repo.GetRefundsDue()
+	if err != nil {
+		return 0, err
+	}
+	for i := range due {
+		p := &due[i]
+		gateway, ok := s.Gateways[p.Provider]
+		if !ok {
+			continue
+		}
+		if err := s.refundDue(gateway, p); err != nil {
+			log.Printf("Warning: Failed to refund payment %d: %v", p.ID, err)
+		}
+	}
+
+	var expired int64
+	authorized, err := s.Repo.GetAuthorized()
+	if err != nil {
+		return 0, err
+	}
+	for i := range authorized {
+		p := &authorized[i]
+		gateway, ok := s.Gateways[p.Provider]
+		if !ok {
+			continue
+		}
+		err := s.capture(gateway, p)
+		if err == nil {
+			continue
+		}
+		log.Printf("Warning: Failed to capture payment %d: %v", p.ID, err)
+		if !p.CreatedAt.Before(deadline) {
+			continue
+		}
+		// Should the provider capture it anyway, the webhook refunds it
+		if err := gateway.Void(*p.ProviderRef); err != nil {
+			log.Printf("Warning: Failed to void payment %d: %v", p.ID, err)
+		}
+		moved, err := s.Repo.MarkFailed(p.ID, domain.StatusAuthorized, "payment could not be captured")
+		if err != nil {
+			return expired, err
+		}
+		if moved {
+			expired++
+		}
+	}
+
+	payments, err := s.Repo.GetPendingBefore(deadline)
+	if err != nil {
+		return expired, err
+	}
+	for _, p := range payments {
+		// A webhook may have settled the payment since it was listed
+		moved, err := s.Repo.MarkFailed(p.ID, domain.StatusPending, "payment timed out")
+		if err != nil {
+			return expired, err
+		}
+		if moved {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// capture captures an authorized payment at its provider, which activates its
+// ticket.
+func (s *PaymentService) capture(gateway domain.PaymentGateway, payment *domain.Payment) error {
+	if err := gateway.Capture(*payment.ProviderRef, payment.Amount); err != nil {
+		return fmt.Errorf("failed to capture payment: %w", err)
+	}
+	_, err := s.Repo.MarkCaptured(payment.ID, domain.StatusAuthorized)
+	return err
+}
+
+// refundDue refunds the whole amount of a payment captured after it failed.
+// The payment is claimed first, so the webhook and the expiry job cannot both
+// refund it.
+func (s *PaymentService) refundDue(gateway domain.PaymentGateway, payment *domain.Payment) error {
+	claimed, err := s.Repo.UpdateStatus(payment.ID, domain.StatusRefundDue, domain.StatusRefunding)
+	if err != nil || !claimed {
+		return err
+	}
+	if err := gateway.Refund(*payment.ProviderRef, payment.Amount); err != nil {
+		if _, releaseErr := s.Repo.UpdateStatus(payment.ID, domain.StatusRefunding, domain.StatusRefundDue); releaseErr != nil {
+			return releaseErr
+		}
+		return fmt.Errorf("failed to refund payment: %w", err)
+	}
+	_, err = s.Repo.MarkRefunded(payment.ID)
+	return err
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRuleNotFound    = errors.New("price rule not found")
+	ErrHolidayNotFound = errors.New("holiday not found")
+	ErrHolidayExists   = errors.New("holiday already exists")
+	ErrInvalidRule     = errors.New("invalid price rule")
+)
+
+// How a rule changes the running price.
+const (
+	AdjustAdd      = "add"      // price + amount (negative for discounts)
+	AdjustMultiply = "multiply" // price * amount
+	AdjustSet      = "set"      // amount, replacing whatever came before
+)
+
+// Day types a rule can be limited to. Holidays are priced like weekends, so a
+// weekend rule also matches holidays, while a holiday rule only matches them.
+const (
+	DayWeekday = "weekday"
+	DayWeekend = "weekend"
+	DayHoliday = "holiday"
+)
+
+// PriceRule adjusts the cinema's base price when all of its non-empty
+// conditions match. Rules apply in ascending Priority, then ID.
+type PriceRule struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
+	SeatType    string    `gorm:"type:varchar(20)" json:"seat_type"`    // standard, premium, couple, ...
+	TheaterType string    `gorm:"type:varchar(50)" json:"theater_type"` // Regular, IMAX, Premiere
+	DayType     string    `gorm:"type:varchar(10)" json:"day_type"`
+	StartTime   string    `gorm:"type:varchar(5)" json:"start_time"` // "HH:MM", inclusive
+	EndTime     string    `gorm:"type:varchar(5)" json:"end_time"`   // "HH:MM", exclusive; may wrap past midnight
+	MovieID     *int64    `json:"movie_id"`
+	Adjustment  string    `gorm:"type:varchar(10);not null" json:"adjustment"`
+	Amount      float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Priority    int       `gorm:"not null" json:"priority"`
+	Active      bool      `gorm:"not null" json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Holiday marks a date that is priced as a holiday.
+type Holiday struct {
+	Date time.Time `gorm:"type:date;primaryKey"`
+	Name string    `gorm:"type:varchar(100);not null"`
+}
+
+// QuoteInput describes one seat of one showtime.
+type QuoteInput struct {
+	BasePrice   float64
+	SeatType    string
+	TheaterType string
+	MovieID     int64
+	StartTime   time.Time // In the cinema's local time
+}
+
+// Quote is the price of a seat together with the rules that produced it.
+type Quote struct {
+	BasePrice float64       `json:"base_price"`
+	Price     float64       `json:"price"`
+	DayType   string        `json:"day_type"`
+	Applied   []AppliedRule `json:"applied_rules"`
+}
+
+type AppliedRule struct {
+	RuleID     int64   `json:"rule_id"`
+	Name       string  `json:"name"`
+	Adjustment string  `json:"adjustment"`
+	Amount     float64 `json:"amount"`
+	PriceAfter float64 `json:"price_after"`
+}
+
+type PricingRepository interface {
+	GetRules() ([]PriceRule, error)
+	GetActiveRules() ([]PriceRule, error)
+	GetRuleByID(id int64) (*PriceRule, error)
+	CreateRule(rule *PriceRule) error
+	UpdateRule(rule *PriceRule) error
+	DeleteRule(id int64) error
+	// GetHolidayDates returns the holidays from from to to, inclusive. Dates
+	// are "2006-01-02" strings so no time zone is involved
+	GetHolidayDates(from, to string) ([]string, error)
+	GetHolidays() ([]Holiday, error)
+	CreateHoliday(holiday *Holiday) error
+	DeleteHoliday(date string) error
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+)
+
+// PriceRuleRequest creates or replaces a rule. Empty conditions match
+// everything; StartTime and EndTime bound a time-of-day window together.
+type PriceRuleRequest struct {
+	Name        string  `json:"name" validate:"required,max=100"`
+	SeatType    string  `json:"seat_type" validate:"omitempty,oneof=standard premium couple wheelchair companion"`
+	TheaterType string  `json:"theater_type" validate:"omitempty,oneof=Regular IMAX Premiere"`
+	DayType     string  `json:"day_type" validate:"omitempty,oneof=weekday weekend holiday"`
+	StartTime   string  `json:"start_time" validate:"required_with=EndTime,omitempty,datetime=15:04"`
+	EndTime     string  `json:"end_time" validate:"required_with=StartTime,omitempty,datetime=15:04"`
+	MovieID     *int64  `json:"movie_id" validate:"omitempty,gt=0"`
+	Adjustment  string  `json:"adjustment" validate:"required,oneof=add multiply set"`
+	Amount      float64 `json:"amount"`
+	Priority    int     `json:"priority"`
+	Active      *bool   `json:"active"` // Defaults to true
+}
+
+type HolidayRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type HolidayResponse struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+// QuoteRequest previews the price of a seat without a showtime, for checking
+// rules before they go live.
+type QuoteRequest struct {
+	BasePrice   float64 `json:"base_price" validate:"gte=0"`
+	SeatType    string  `json:"seat_type" validate:"required,oneof=standard premium couple wheelchair companion"`
+	TheaterType string  `json:"theater_type" validate:"required,oneof=Regular IMAX Premiere"`
+	MovieID     int64   `json:"movie_id" validate:"gte=0"`
+	StartTime   string  `json:"start_time" validate:"required,datetime=2006-01-02T15:04:05Z07:00"` // RFC3339
+}
+
+func ToHolidayResponse(h domain.Holiday) HolidayResponse {
+	return HolidayResponse{
+		Date: h.Date.Format("2006-01-02"),
+		Name: h.Name,
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/service"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PricingHandler struct {
+	Service   *service.PricingService
+	Validator *validator.Validate
+}
+
+func NewPricingHandler(service *service.PricingService, v *validator.Validate) *PricingHandler {
+	return &PricingHandler{Service: service, Validator: v}
+}
+
+// RegisterRoutes mounts the admin pricing routes. Prices are chain-wide, so
+// cinema staff cannot change them.
+func (h *PricingHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	pricing := app.Group("/admin/pricing", auth, middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin))
+	pricing.Get("/rules", h.handleGetRules)
+	pricing.Post("/rules", h.handleCreateRule)
+	pricing.Put("/rules/:id", h.handleUpdateRule)
+	pricing.Delete("/rules/:id", h.handleDeleteRule)
+
+	pricing.Get("/holidays", h.handleGetHolidays)
+	pricing.Post("/holidays", h.handleCreateHoliday)
+	pricing.Delete("/holidays/:date", h.handleDeleteHoliday)
+
+	pricing.Post("/quote", h.handleQuote)
+}
+
+func (h *PricingHandler) handleGetRules(c *fiber.Ctx) error {
+	resp, err := h.Service.GetRules()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PricingHandler) handleCreateRule(c *fiber.Ctx) error {
+	var req dto.PriceRuleRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateRule(req)
+	if err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *PricingHandler) handleUpdateRule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.PriceRuleRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.UpdateRule(id, req)
+	if err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PricingHandler) handleDeleteRule(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.DeleteRule(id); err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *PricingHandler) handleGetHolidays(c *fiber.Ctx) error {
+	resp, err := h.Service.GetHolidays()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PricingHandler) handleCreateHoliday(c *fiber.Ctx) error {
+	var req dto.HolidayRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateHoliday(req)
+	if err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *PricingHandler) handleDeleteHoliday(c *fiber.Ctx) error {
+	date := c.Params("date")
+	if err := h.Validator.Var(date, "datetime=2006-01-02"); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid date")
+	}
+
+	if err := h.Service.DeleteHoliday(date); err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *PricingHandler) handleQuote(c *fiber.Ctx) error {
+	var req dto.QuoteRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.PreviewQuote(req)
+	if err != nil {
+		return c.Status(pricingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *PricingHandler) parseAndValidate(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+	return h.Validator.Struct(req)
+}
+
+func pricingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrRuleNotFound), errors.Is(err, domain.ErrHolidayNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidRule):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrHolidayExists):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
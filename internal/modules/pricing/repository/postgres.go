@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+type PostgresPricingRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresPricingRepository(db *gorm.DB) *PostgresPricingRepository {
+	return &PostgresPricingRepository{DB: db}
+}
+
+func (r *PostgresPricingRepository) GetRules() ([]domain.PriceRule, error) {
+	var rules []domain.PriceRule
+	if err := r.DB.Order("priority, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *PostgresPricingRepository) GetActiveRules() ([]domain.PriceRule, error) {
+	var rules []domain.PriceRule
+	if err := r.DB.Where("active = ?", true).Order("priority, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *PostgresPricingRepository) GetRuleByID(id int64) (*domain.PriceRule, error) {
+	var rule domain.PriceRule
+	if err := r.DB.First(&rule, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PostgresPricingRepository) CreateRule(rule *domain.PriceRule) error {
+	return r.DB.Create(rule).Error
+}
+
+func (r *PostgresPricingRepository) UpdateRule(rule *domain.PriceRule) error {
+	// Save writes zero values too, so a condition can be cleared
+	return r.DB.Save(rule).Error
+}
+
+func (r *PostgresPricingRepository) DeleteRule(id int64) error {
+	result := r.DB.Delete(&domain.PriceRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrRuleNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPricingRepository) GetHolidayDates(from, to string) ([]string, error) {
+	var dates []string
+	err := r.DB.Model(&domain.Holiday{}).
+		Where("date BETWEEN ? AND ?", from, to).
+		Pluck("to_char(date, 'YYYY-MM-DD')", &dates).Error
+	if err != nil {
+		return nil, err
+	}
+	return dates, nil
+}
+
+func (r *PostgresPricingRepository) GetHolidays() ([]domain.Holiday, error) {
+	var holidays []domain.Holiday
+	if err := r.DB.Order("date").Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	return holidays, nil
+}
+
+func (r *PostgresPricingRepository) CreateHoliday(holiday *domain.Holiday) error {
+	if err := r.DB.Create(holiday).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrHolidayExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PostgresPricingRepository) DeleteHoliday(date string) error {
+	result := r.DB.Where("date = ?", date).Delete(&domain.Holiday{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrHolidayNotFound
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/pricing/dto"
+)
+
+type PricingService struct {
+	Repo domain.PricingRepository
+}
+
+func NewPricingService(repo domain.PricingRepository) *PricingService {
+	return &PricingService{Repo: repo}
+}
+
+// Quote prices a single seat.
+func (s *PricingService) Quote(in domain.QuoteInput) (*domain.Quote, error) {
+	quotes, err := s.QuoteSeatTypes(in, []string{in.SeatType})
+	if err != nil {
+		return nil, err
+	}
+	quote := quotes[in.SeatType]
+	return &quote, nil
+}
+
+// QuoteSeatTypes prices every seat type of one showtime while loading the
+// rules and holiday calendar only once. in.SeatType is ignored.
+func (s *PricingService) QuoteSeatTypes(in domain.QuoteInput, seatTypes []string) (map[string]domain.Quote, error) {
+	pricer, err := s.NewPricer(in.StartTime, in.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	return pricer.QuoteSeatTypes(in, seatTypes), nil
+}
+
+// Pricer quotes any number of showtimes from a single load of the active rules
+// and the holidays of a date range, for listings that price many at once.
+type Pricer struct {
+	rules    []domain.PriceRule
+	holidays map[string]bool
+}
+
+// NewPricer loads what quoting showtimes that start from first to last, in
+// local time, needs. Showtimes outside those dates are never priced as
+// holidays.
+func (s *PricingService) NewPricer(first, last time.Time) (*Pricer, error) {
+	rules, err := s.Repo.GetActiveRules()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price rules: %w", err)
+	}
+	dates, err := s.Repo.GetHolidayDates(first.Format("2006-01-02"), last.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check holidays: %w", err)
+	}
+
+	holidays := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		holidays[d] = true
+	}
+	return &Pricer{rules: rules, holidays: holidays}, nil
+}
+
+func (p *Pricer) Quote(in domain.QuoteInput) domain.Quote {
+	return evaluate(p.rules, in, p.dayType(in.StartTime))
+}
+
+// QuoteSeatTypes prices every seat type of one showtime. in.SeatType is
+// ignored.
+func (p *Pricer) QuoteSeatTypes(in domain.QuoteInput, seatTypes []string) map[string]domain.Quote {
+	dayType := p.dayType(in.StartTime)
+	quotes := make(map[string]domain.Quote, len(seatTypes))
+	for _, seatType := range seatTypes {
+		if _, ok := quotes[seatType]; ok {
+			continue
+		}
+		seatIn := in
+		seatIn.SeatType = seatType
+		quotes[seatType] = evaluate(p.rules, seatIn, dayType)
+	}
+	return quotes
+}
+
+func (p *Pricer) dayType(start time.Time) string {
+	switch {
+	case p.holidays[start.Format("2006-01-02")]:
+		return domain.DayHoliday
+	case start.Weekday() == time.Saturday || start.Weekday() == time.Sunday:
+		return domain.DayWeekend
+	default:
+		return domain.DayWeekday
+	}
+}
+
+// evaluate runs the rules, already sorted by priority, over the base price.
+func evaluate(rules []domain.PriceRule, in domain.QuoteInput, dayType string) domain.Quote {
+	quote := domain.Quote{BasePrice: in.BasePrice, Price: in.BasePrice, DayType: dayType, Applied: []domain.AppliedRule{}}
+	for _, rule := range rules {
+		if !matches(rule, in, dayType) {
+			continue
+		}
+		switch rule.Adjustment {
+		case domain.AdjustAdd:
+			quote.Price += rule.Amount
+		case domain.AdjustMultiply:
+			quote.Price *= rule.Amount
+		case domain.AdjustSet:
+			quote.Price = rule.Amount
+		}
+		// Whole rupiah, and never below free
+		quote.Price = math.Max(0, math.Round(quote.Price))
+
+		quote.Applied = append(quote.Applied, domain.AppliedRule{
+			RuleID:     rule.ID,
+			Name:       rule.Name,
+			Adjustment: rule.Adjustment,
+			Amount:     rule.Amount,
+			PriceAfter: quote.Price,
+		})
+	}
+	return quote
+}
+
+func matches(rule domain.PriceRule, in domain.QuoteInput, dayType string) bool {
+	if rule.SeatType != "" && rule.SeatType != in.SeatType {
+		return false
+	}
+	if rule.TheaterType != "" && rule.TheaterType != in.TheaterType {
+		return false
+	}
+	if rule.MovieID != nil && *rule.MovieID != in.MovieID {
+		return false
+	}
+
+	switch rule.DayType {
+	case domain.DayWeekday:
+		if dayType != domain.DayWeekday {
+			return false
+		}
+	case domain.DayWeekend:
+		if dayType == domain.DayWeekday {
+			return false
+		}
+	case domain.DayHoliday:
+		if dayType != domain.DayHoliday {
+			return false
+		}
+	}
+
+	if rule.StartTime != "" && rule.EndTime != "" {
+		// "HH:MM" strings compare correctly as text
+		clock := in.StartTime.Format("15:04")
+		if rule.StartTime <= rule.EndTime {
+			if clock < rule.StartTime || clock >= rule.EndTime {
+				return false
+			}
+		} else if clock < rule.StartTime && clock >= rule.EndTime {
+			// Window wraps past midnight, e.g. 22:00-02:00
+			return false
+		}
+	}
+	return true
+}
+
+func (s *PricingService) GetRules() ([]domain.PriceRule, error) {
+	return s.Repo.GetRules()
+}
+
+func (s *PricingService) CreateRule(req dto.PriceRuleRequest) (*domain.PriceRule, error) {
+	rule := &domain.PriceRule{}
+	if err := applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.CreateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *PricingService) UpdateRule(id int64, req dto.PriceRuleRequest) (*domain.PriceRule, error) {
+	rule, err := s.Repo.GetRuleByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.UpdateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *PricingService) DeleteRule(id int64) error {
+	return s.Repo.DeleteRule(id)
+}
+
+func applyRuleRequest(rule *domain.PriceRule, req dto.PriceRuleRequest) error {
+	if req.Adjustment == domain.AdjustMultiply && req.Amount < 0 {
+		return fmt.Errorf("%w: multiplier must not be negative", domain.ErrInvalidRule)
+	}
+	if req.Adjustment == domain.AdjustSet && req.Amount < 0 {
+		return fmt.Errorf("%w: price must not be negative", domain.ErrInvalidRule)
+	}
+
+	rule.Name = req.Name
+	rule.SeatType = req.SeatType
+	rule.TheaterType = req.TheaterType
+	rule.DayType = req.DayType
+	rule.StartTime = req.StartTime
+	rule.EndTime = req.EndTime
+	rule.MovieID = req.MovieID
+	rule.Adjustment = req.Adjustment
+	rule.Amount = req.Amount
+	rule.Priority = req.Priority
+	rule.Active = req.Active == nil || *req.Active
+	return nil
+}
+
+func (s *PricingService) GetHolidays() ([]dto.HolidayResponse, error) {
+	holidays, err := s.Repo.GetHolidays()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.HolidayResponse, len(holidays))
+	for i, h := range holidays {
+		resp[i] = dto.ToHolidayResponse(h)
+	}
+	return resp, nil
+}
+
+func (s *PricingService) CreateHoliday(req dto.HolidayRequest) (*dto.HolidayResponse, error) {
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, err
+	}
+	holiday := &domain.Holiday{Date: date, Name: req.Name}
+	if err := s.Repo.CreateHoliday(holiday); err != nil {
+		return nil, err
+	}
+
+	resp := dto.ToHolidayResponse(*holiday)
+	return &resp, nil
+}
+
+func (s *PricingService) DeleteHoliday(date string) error {
+	return s.Repo.DeleteHoliday(date)
+}
+
+// PreviewQuote prices a hypothetical seat for the admin quote endpoint.
+func (s *PricingService) PreviewQuote(req dto.QuoteRequest) (*domain.Quote, error) {
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	return s.Quote(domain.QuoteInput{
+		BasePrice:   req.BasePrice,
+		SeatType:    req.SeatType,
+		TheaterType: req.TheaterType,
+		MovieID:     req.MovieID,
+		StartTime:   start,
+	})
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrNotWatched      = errors.New("only moviegoers with a completed ticket can review the movie")
+	ErrAlreadyReviewed = errors.New("movie already reviewed, edit the review instead")
+	ErrOwnReview       = errors.New("cannot vote on your own review")
+	ErrInvalidSort     = errors.New("sort must be recent or helpful")
+)
+
+// Review moderation states. Only published reviews are listed and counted in
+// the movie's rating.
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	StatusRejected  = "rejected"
+)
+
+// Vote kinds.
+const (
+	VoteHelpful = "helpful"
+	VoteReport  = "report"
+)
+
+// Review is a user's 1-10 rating of a movie, with an optional text. Each user
+// reviews a movie at most once.
+type Review struct {
+	ID      int64           `gorm:"primaryKey" json:"id"`
+	MovieID int64           `gorm:"not null;uniqueIndex:idx_reviews_movie_user" json:"movie_id"`
+	UserID  int64           `gorm:"not null;uniqueIndex:idx_reviews_movie_user;index" json:"user_id"`
+	User    userDomain.User `gorm:"foreignKey:UserID" json:"-"`
+	Rating  int             `gorm:"not null" json:"rating"`
+	Body    string          `gorm:"type:text;not null;default:''" json:"body"`
+	Status  string          `gorm:"type:varchar(20);not null;index" json:"status"`
+	// HelpfulCount and ReportCount mirror the review's votes
+	HelpfulCount   int       `gorm:"not null;default:0" json:"helpful_count"`
+	ReportCount    int       `gorm:"not null;default:0" json:"report_count"`
+	ModerationNote string    `gorm:"type:text;not null;default:''" json:"moderation_note"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// EditedAt is when the author last changed the rating or text
+	EditedAt *time.Time `json:"edited_at"`
+}
+
+// Counted reports whether the review's rating is part of its movie's
+// aggregate. A nil review is not.
+func (r *Review) Counted() bool {
+	return r != nil && r.Status == StatusPublished
+}
+
+// InitialStatus is where a new or edited review starts: a bare rating needs
+// no moderation, a text does.
+func InitialStatus(body string) string {
+	if body == "" {
+		return StatusPublished
+	}
+	return StatusPending
+}
+
+// ReviewVote is a user marking a review helpful or reporting it.
+type ReviewVote struct {
+	ID        int64     `gorm:"primaryKey" json:"id"`
+	ReviewID  int64     `gorm:"not null;uniqueIndex:idx_review_votes_review_user_kind" json:"review_id"`
+	UserID    int64     `gorm:"not null;uniqueIndex:idx_review_votes_review_user_kind" json:"user_id"`
+	Kind      string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_review_votes_review_user_kind" json:"kind"`
+	Reason    string    `gorm:"type:text;not null;default:''" json:"reason,omitempty"` // For reports
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RatingPrior is the Bayesian prior of movie ratings: a movie's average is
+// pulled toward Mean as if it had Weight extra votes of Mean, so a handful of
+// votes cannot put it at the top.
+type RatingPrior struct {
+	Mean   float64
+	Weight int
+}
+
+// Average is the rating of a movie with count votes adding up to sum, to one
+// decimal.
+func (p RatingPrior) Average(sum, count int) float64 {
+	avg := (p.Mean*float64(p.Weight) + float64(sum)) / float64(p.Weight+count)
+	return math.Round(avg*10) / 10
+}
+
+// Review list sorts.
+const (
+	SortRecent  = "recent"
+	SortHelpful = "helpful"
+)
+
+type ReviewRepository interface {
+	// HasCompletedTicket reports whether the user watched the movie.
+	HasCompletedTicket(userID, movieID int64) (bool, error)
+	GetByID(id int64) (*Review, error)
+	GetByMovieAndUser(movieID, userID int64) (*Review, error)
+	// Create inserts the review and, if it is counted, adds it to the movie's
+	// rating. A second review of the movie by the user is ErrAlreadyReviewed.
+	Create(review *Review, prior RatingPrior) error
+	// Modify applies fn to the review while holding it locked, saves it and
+	// moves the movie's rating by the difference fn made to it.
+	Modify(id int64, prior RatingPrior, fn func(review *Review) error) (*Review, error)
+
+	// ListByMovie returns a page of the movie's published reviews after the
+	// encoded cursor, by sort.
+	ListByMovie(movieID int64, sort, cursor string, limit int) ([]Review, pagination.Meta, error)
+	// ListByStatus returns a page of reviews in the status, oldest first.
+	ListByStatus(status, cursor string, limit int) ([]Review, pagination.Meta, error)
+
+	// AddVote records the vote and bumps the review's counter, reporting
+	// whether the vote is new.
+	AddVote(vote *ReviewVote) (bool, error)
+	// RemoveVote deletes the vote and lowers the counter, reporting whether
+	// there was one.
+	RemoveVote(reviewID, userID int64, kind string) (bool, error)
+}
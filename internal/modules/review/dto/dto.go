@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=10"`
+	Body   string `json:"body" validate:"max=5000"` // Optional; a text goes through moderation
+}
+
+type ReportRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+type ModerationRequest struct {
+	Status string `json:"status" validate:"required,oneof=published rejected"`
+	Note   string `json:"note" validate:"max=500"` // Shown to the author
+}
+
+// ReviewResponse is a review as everyone sees it.
+type ReviewResponse struct {
+	ID           int64      `json:"id"`
+	MovieID      int64      `json:"movie_id"`
+	Author       string     `json:"author"`
+	Rating       int        `json:"rating"`
+	Body         string     `json:"body"`
+	HelpfulCount int        `json:"helpful_count"`
+	CreatedAt    time.Time  `json:"created_at"`
+	EditedAt     *time.Time `json:"edited_at"`
+}
+
+// ReviewDetailResponse adds the moderation state, for the author and moderators.
+type ReviewDetailResponse struct {
+	ReviewResponse
+	Status         string `json:"status"`
+	ModerationNote string `json:"moderation_note,omitempty"`
+	ReportCount    int    `json:"report_count"`
+}
+
+type ReviewListResponse struct {
+	Reviews []ReviewResponse `json:"reviews"`
+	Cursor  pagination.Meta  `json:"cursor"`
+}
+
+type ModerationQueueResponse struct {
+	Reviews []ReviewDetailResponse `json:"reviews"`
+	Cursor  pagination.Meta        `json:"cursor"`
+}
+
+func ToReviewResponse(r domain.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:           r.ID,
+		MovieID:      r.MovieID,
+		Author:       r.User.Name,
+		Rating:       r.Rating,
+		Body:         r.Body,
+		HelpfulCount: r.HelpfulCount,
+		CreatedAt:    r.CreatedAt,
+		EditedAt:     r.EditedAt,
+	}
+}
+
+func ToReviewDetailResponse(r domain.Review) ReviewDetailResponse {
+	return ReviewDetailResponse{
+		ReviewResponse: ToReviewResponse(r),
+		Status:         r.Status,
+		ModerationNote: r.ModerationNote,
+		ReportCount:    r.ReportCount,
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/service"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewHandler struct {
+	Service   *service.ReviewService
+	Validator *validator.Validate
+}
+
+func NewReviewHandler(s *service.ReviewService, v *validator.Validate) *ReviewHandler {
+	return &ReviewHandler{Service: s, Validator: v}
+}
+
+func (h *ReviewHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	movieReviews := app.Group("/movies/:id/reviews")
+	movieReviews.Get("/", h.handleGetMovieReviews)
+	movieReviews.Post("/", auth, h.handleCreateReview)
+	movieReviews.Get("/mine", auth, h.handleGetMyReview)
+	movieReviews.Put("/mine", auth, h.handleUpdateMyReview)
+
+	reviews := app.Group("/reviews/:id", auth)
+	reviews.Post("/helpful", h.handleMarkHelpful)
+	reviews.Delete("/helpful", h.handleUnmarkHelpful)
+	reviews.Post("/report", h.handleReport)
+
+	moderation := app.Group("/admin/reviews", auth, middleware.RequireRoles(userDomain.RoleChainAdmin, userDomain.RoleSuperAdmin))
+	moderation.Get("/", h.handleGetModerationQueue)
+	moderation.Put("/:id/moderation", h.handleModerate)
+}
+
+// handleGetMovieReviews lists published reviews by ?sort=recent|helpful,
+// paged with ?cursor= and ?limit=.
+func (h *ReviewHandler) handleGetMovieReviews(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetMovieReviews(movieID, c.Query("sort"), c.Query("cursor"), c.QueryInt("limit"))
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleCreateReview(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	var req dto.ReviewRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CreateReview(middleware.GetUserID(c), movieID, req)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *ReviewHandler) handleGetMyReview(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.GetMyReview(middleware.GetUserID(c), movieID)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleUpdateMyReview(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	var req dto.ReviewRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.UpdateMyReview(middleware.GetUserID(c), movieID, req)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleMarkHelpful(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.MarkHelpful(middleware.GetUserID(c), id)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleUnmarkHelpful(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	resp, err := h.Service.UnmarkHelpful(middleware.GetUserID(c), id)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleReport(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	// The reason is optional, and so is the body
+	var req dto.ReportRequest
+	if len(c.Body()) > 0 {
+		if err := h.parseAndValidate(c, &req); err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+		}
+	}
+
+	if err := h.Service.ReportReview(middleware.GetUserID(c), id, req); err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// handleGetModerationQueue lists reviews by ?status= (pending by default),
+// oldest first, paged with ?cursor= and ?limit=.
+func (h *ReviewHandler) handleGetModerationQueue(c *fiber.Ctx) error {
+	status := c.Query("status")
+	if err := h.Validator.Var(status, "omitempty,oneof=pending published rejected"); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("status must be pending, published or rejected")
+	}
+
+	resp, err := h.Service.GetModerationQueue(status, c.Query("cursor"), c.QueryInt("limit"))
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) handleModerate(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+	var req dto.ModerationRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.Moderate(id, req)
+	if err != nil {
+		return c.Status(reviewErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *ReviewHandler) parseAndValidate(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+	return h.Validator.Struct(req)
+}
+
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrReviewNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrNotWatched), errors.Is(err, domain.ErrOwnReview):
+		return fiber.StatusForbidden
+	case errors.Is(err, domain.ErrAlreadyReviewed):
+		return fiber.StatusConflict
+	case errors.Is(err, domain.ErrInvalidSort), errors.Is(err, pagination.ErrInvalidCursor):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/domain"
+	ticketDomain "github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresReviewRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresReviewRepository(db *gorm.DB) *PostgresReviewRepository {
+	return &PostgresReviewRepository{DB: db}
+}
+
+var reviewSorts = map[string]pagination.Order{
+	domain.SortRecent:  {Name: domain.SortRecent, Key: "reviews.created_at", KeyType: "timestamptz", ID: "reviews.id", Desc: true},
+	domain.SortHelpful: {Name: domain.SortHelpful, Key: "reviews.helpful_count", KeyType: "bigint", ID: "reviews.id", Desc: true},
+}
+
+// queueOrder serves the moderation queue first come, first served.
+var queueOrder = pagination.Order{Name: "queue", Key: "reviews.created_at", KeyType: "timestamptz", ID: "reviews.id"}
+
+// voteCounters are the review columns mirroring each kind of vote.
+var voteCounters = map[string]string{
+	domain.VoteHelpful: "helpful_count",
+	domain.VoteReport:  "report_count",
+}
+
+func (r *PostgresReviewRepository) HasCompletedTicket(userID, movieID int64) (bool, error) {
+	var watched bool
+	err := r.DB.Raw("SELECT EXISTS (SELECT 1 FROM tickets WHERE user_id = ? AND movie_id = ? AND status = ?)",
+		userID, movieID, ticketDomain.StatusCompleted).Scan(&watched).Error
+	return watched, err
+}
+
+func (r *PostgresReviewRepository) GetByID(id int64) (*domain.Review, error) {
+	var review domain.Review
+	if err := r.DB.Preload("User").First(&review, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *PostgresReviewRepository) GetByMovieAndUser(movieID, userID int64) (*domain.Review, error) {
+	var review domain.Review
+	if err := r.DB.Preload("User").Where("movie_id = ? AND user_id = ?", movieID, userID).First(&review).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrReviewNotFound
+		}
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *PostgresReviewRepository) Create(review *domain.Review, prior domain.RatingPrior) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(review).Error; err != nil {
+			if isUniqueViolation(err) {
+				return domain.ErrAlreadyReviewed
+			}
+			return err
+		}
+		return moveRating(tx, review.MovieID, nil, review, prior)
+	})
+}
+
+func (r *PostgresReviewRepository) Modify(id int64, prior domain.RatingPrior, fn func(review *domain.Review) error) (*domain.Review, error) {
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var review domain.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrReviewNotFound
+			}
+			return err
+		}
+
+		before := review
+		if err := fn(&review); err != nil {
+			return err
+		}
+		err := tx.Model(&review).
+			Select("rating", "body", "status", "moderation_note", "edited_at", "updated_at").
+			Omit(clause.Associations).
+			Updates(&review).Error
+		if err != nil {
+			return err
+		}
+		return moveRating(tx, review.MovieID, &before, &review, prior)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetByID(id)
+}
+
+// moveRating updates the movie's rating aggregate from before to after,
+// either of which may be nil, without recounting its other reviews.
+func moveRating(tx *gorm.DB, movieID int64, before, after *domain.Review, prior domain.RatingPrior) error {
+	sum, count := 0, 0
+	if before.Counted() {
+		sum -= before.Rating
+		count--
+	}
+	if after.Counted() {
+		sum += after.Rating
+		count++
+	}
+	if sum == 0 && count == 0 {
+		return nil
+	}
+
+	var aggregate struct {
+		RatingSum   int
+		RatingCount int
+	}
+	if err := tx.Raw("SELECT rating_sum, rating_count FROM movies WHERE id = ? FOR UPDATE", movieID).Scan(&aggregate).Error; err != nil {
+		return err
+	}
+	aggregate.RatingSum += sum
+	aggregate.RatingCount += count
+
+	updates := map[string]interface{}{"rating_sum": aggregate.RatingSum, "rating_count": aggregate.RatingCount}
+	if prior.Weight+aggregate.RatingCount > 0 {
+		updates["rating"] = prior.Average(aggregate.RatingSum, aggregate.RatingCount)
+	}
+	return tx.Table("movies").Where("id = ?", movieID).Updates(updates).Error
+}
+
+func (r *PostgresReviewRepository) ListByMovie(movieID int64, sort, cursor string, limit int) ([]domain.Review, pagination.Meta, error) {
+	order, ok := reviewSorts[sort]
+	if !ok {
+		return nil, pagination.Meta{}, domain.ErrInvalidSort
+	}
+	q := r.DB.Model(&domain.Review{}).Where("reviews.movie_id = ? AND reviews.status = ?", movieID, domain.StatusPublished)
+	return r.page(q, order, cursor, limit)
+}
+
+func (r *PostgresReviewRepository) ListByStatus(status, cursor string, limit int) ([]domain.Review, pagination.Meta, error) {
+	q := r.DB.Model(&domain.Review{}).Where("reviews.status = ?", status)
+	return r.page(q, queueOrder, cursor, limit)
+}
+
+func (r *PostgresReviewRepository) page(q *gorm.DB, order pagination.Order, cursor string, limit int) ([]domain.Review, pagination.Meta, error) {
+	after, err := pagination.Decode(cursor, order)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	ids, meta, err := pagination.Keyset(q, order, after, limit)
+	if err != nil || len(ids) == 0 {
+		return nil, meta, err
+	}
+
+	var reviews []domain.Review
+	if err := r.DB.Preload("User").Find(&reviews, ids).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return pagination.Arrange(reviews, ids, func(rv domain.Review) int64 { return rv.ID }), meta, nil
+}
+
+func (r *PostgresReviewRepository) AddVote(vote *domain.ReviewVote) (bool, error) {
+	counter := voteCounters[vote.Kind]
+	added := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(vote)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		added = true
+		return tx.Model(&domain.Review{}).Where("id = ?", vote.ReviewID).
+			UpdateColumn(counter, gorm.Expr(counter+" + 1")).Error
+	})
+	return added, err
+}
+
+func (r *PostgresReviewRepository) RemoveVote(reviewID, userID int64, kind string) (bool, error) {
+	counter := voteCounters[kind]
+	removed := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ? AND kind = ?", reviewID, userID, kind).Delete(&domain.ReviewVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		removed = true
+		return tx.Model(&domain.Review{}).Where("id = ?", reviewID).
+			UpdateColumn(counter, gorm.Expr("GREATEST("+counter+" - 1, 0)")).Error
+	})
+	return removed, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
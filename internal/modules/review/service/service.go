@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/review/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+type ReviewService struct {
+	Repo  domain.ReviewRepository
+	Prior domain.RatingPrior
+	// ReportThreshold is how many reports send a published review back to
+	// moderation
+	ReportThreshold int
+}
+
+func NewReviewService(repo domain.ReviewRepository, prior domain.RatingPrior, reportThreshold int) *ReviewService {
+	return &ReviewService{Repo: repo, Prior: prior, ReportThreshold: reportThreshold}
+}
+
+// CreateReview posts userID's review of a movie they have a completed ticket
+// for. Ratings without a text are published at once.
+func (s *ReviewService) CreateReview(userID, movieID int64, req dto.ReviewRequest) (*dto.ReviewDetailResponse, error) {
+	watched, err := s.Repo.HasCompletedTicket(userID, movieID)
+	if err != nil {
+		return nil, err
+	}
+	if !watched {
+		return nil, domain.ErrNotWatched
+	}
+
+	body := strings.TrimSpace(req.Body)
+	review := &domain.Review{
+		MovieID: movieID,
+		UserID:  userID,
+		Rating:  req.Rating,
+		Body:    body,
+		Status:  domain.InitialStatus(body),
+	}
+	if err := s.Repo.Create(review, s.Prior); err != nil {
+		return nil, err
+	}
+	return s.detail(review.ID)
+}
+
+// UpdateMyReview edits userID's review of the movie. A changed text, or any
+// edit of a rejected review, goes back through moderation.
+func (s *ReviewService) UpdateMyReview(userID, movieID int64, req dto.ReviewRequest) (*dto.ReviewDetailResponse, error) {
+	existing, err := s.Repo.GetByMovieAndUser(movieID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(req.Body)
+	review, err := s.Repo.Modify(existing.ID, s.Prior, func(r *domain.Review) error {
+		if r.Body != body || r.Status == domain.StatusRejected {
+			r.Status = domain.InitialStatus(body)
+			r.ModerationNote = ""
+		}
+		now := time.Now()
+		r.Rating, r.Body, r.EditedAt = req.Rating, body, &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToReviewDetailResponse(*review)
+	return &resp, nil
+}
+
+func (s *ReviewService) GetMyReview(userID, movieID int64) (*dto.ReviewDetailResponse, error) {
+	review, err := s.Repo.GetByMovieAndUser(movieID, userID)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToReviewDetailResponse(*review)
+	return &resp, nil
+}
+
+// GetMovieReviews lists the movie's published reviews a page at a time.
+func (s *ReviewService) GetMovieReviews(movieID int64, sort, cursor string, limit int) (*dto.ReviewListResponse, error) {
+	if sort == "" {
+		sort = domain.SortRecent
+	}
+	reviews, meta, err := s.Repo.ListByMovie(movieID, sort, cursor, pagination.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ReviewListResponse{Reviews: []dto.ReviewResponse{}, Cursor: meta}
+	for _, r := range reviews {
+		resp.Reviews = append(resp.Reviews, dto.ToReviewResponse(r))
+	}
+	return resp, nil
+}
+
+// MarkHelpful records that userID found a published review helpful; marking
+// it twice counts once.
+func (s *ReviewService) MarkHelpful(userID, reviewID int64) (*dto.ReviewResponse, error) {
+	if err := s.checkVotable(userID, reviewID); err != nil {
+		return nil, err
+	}
+	if _, err := s.Repo.AddVote(&domain.ReviewVote{ReviewID: reviewID, UserID: userID, Kind: domain.VoteHelpful}); err != nil {
+		return nil, err
+	}
+	return s.public(reviewID)
+}
+
+// UnmarkHelpful takes back userID's helpful vote on a published review.
+func (s *ReviewService) UnmarkHelpful(userID, reviewID int64) (*dto.ReviewResponse, error) {
+	if _, err := s.public(reviewID); err != nil {
+		return nil, err
+	}
+	if _, err := s.Repo.RemoveVote(reviewID, userID, domain.VoteHelpful); err != nil {
+		return nil, err
+	}
+	return s.public(reviewID)
+}
+
+// ReportReview records userID's report of a published review. Once it has
+// ReportThreshold reports it is hidden until a moderator looks at it again.
+func (s *ReviewService) ReportReview(userID, reviewID int64, req dto.ReportRequest) error {
+	if err := s.checkVotable(userID, reviewID); err != nil {
+		return err
+	}
+	added, err := s.Repo.AddVote(&domain.ReviewVote{
+		ReviewID: reviewID,
+		UserID:   userID,
+		Kind:     domain.VoteReport,
+		Reason:   strings.TrimSpace(req.Reason),
+	})
+	if err != nil || !added {
+		return err
+	}
+
+	review, err := s.Repo.GetByID(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.Status != domain.StatusPublished || review.ReportCount < s.ReportThreshold {
+		return nil
+	}
+	_, err = s.Repo.Modify(reviewID, s.Prior, func(r *domain.Review) error {
+		// Checked again under the lock: another report may have hidden it
+		if r.Status == domain.StatusPublished && r.ReportCount >= s.ReportThreshold {
+			r.Status = domain.StatusPending
+			r.ModerationNote = fmt.Sprintf("Hidden after %d reports", r.ReportCount)
+		}
+		return nil
+	})
+	return err
+}
+
+// GetModerationQueue lists reviews in status (pending when empty), oldest
+// first.
+func (s *ReviewService) GetModerationQueue(status, cursor string, limit int) (*dto.ModerationQueueResponse, error) {
+	if status == "" {
+		status = domain.StatusPending
+	}
+	reviews, meta, err := s.Repo.ListByStatus(status, cursor, pagination.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ModerationQueueResponse{Reviews: []dto.ReviewDetailResponse{}, Cursor: meta}
+	for _, r := range reviews {
+		resp.Reviews = append(resp.Reviews, dto.ToReviewDetailResponse(r))
+	}
+	return resp, nil
+}
+
+// Moderate publishes or rejects a review; published reviews can also be
+// taken down and rejected ones reinstated.
+func (s *ReviewService) Moderate(reviewID int64, req dto.ModerationRequest) (*dto.ReviewDetailResponse, error) {
+	review, err := s.Repo.Modify(reviewID, s.Prior, func(r *domain.Review) error {
+		r.Status = req.Status
+		r.ModerationNote = strings.TrimSpace(req.Note)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToReviewDetailResponse(*review)
+	return &resp, nil
+}
+
+// checkVotable checks userID may vote on the review: it is published and not
+// their own. Hidden reviews look missing.
+func (s *ReviewService) checkVotable(userID, reviewID int64) error {
+	review, err := s.Repo.GetByID(reviewID)
+	if err != nil {
+		return err
+	}
+	if review.Status != domain.StatusPublished {
+		return domain.ErrReviewNotFound
+	}
+	if review.UserID == userID {
+		return domain.ErrOwnReview
+	}
+	return nil
+}
+
+func (s *ReviewService) detail(id int64) (*dto.ReviewDetailResponse, error) {
+	review, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	resp := dto.ToReviewDetailResponse(*review)
+	return &resp, nil
+}
+
+// public returns a published review as anyone may see it; other reviews are
+// not found.
+func (s *ReviewService) public(id int64) (*dto.ReviewResponse, error) {
+	review, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != domain.StatusPublished {
+		return nil, domain.ErrReviewNotFound
+	}
+	resp := dto.ToReviewResponse(*review)
+	return &resp, nil
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnknownTicketCode      = errors.New("ticket not recognized")
+	ErrNotAdmissible          = errors.New("ticket is not valid for admission")
+	ErrWrongCinema            = errors.New("ticket is for a showtime at another cinema")
+	ErrOutsideAdmissionWindow = errors.New("showtime is not open for admission")
+)
+
+// Admission records one seat of a ticket being let in at the gate. The unique
+// index on TicketSeatID is what stops a ticket from being used twice.
+type Admission struct {
+	ID           int64     `gorm:"primaryKey" json:"id"`
+	TicketID     int64     `gorm:"not null;index" json:"ticket_id"`
+	TicketSeatID int64     `gorm:"not null;uniqueIndex" json:"ticket_seat_id"`
+	ShowtimeID   int64     `gorm:"not null" json:"showtime_id"`
+	CinemaID     int64     `gorm:"not null" json:"cinema_id"`
+	ScannerID    int64     `gorm:"not null" json:"scanner_id"`
+	AdmittedAt   time.Time `gorm:"not null" json:"admitted_at"`
+}
+
+// AdmissionWindow is when the gate admits a showtime's tickets, relative to
+// its start time.
+type AdmissionWindow struct {
+	OpensBefore time.Duration
+	ClosesAfter time.Duration
+}
+
+func (w AdmissionWindow) Contains(start, now time.Time) bool {
+	return !now.Before(start.Add(-w.OpensBefore)) && !now.After(start.Add(w.ClosesAfter))
+}
+
+// AlreadyAdmittedError reports seats that were already let in, and where and
+// when that happened first.
+type AlreadyAdmittedError struct {
+	Seats      []string
+	AdmittedAt time.Time
+	Scanner    string
+	Cinema     string
+}
+
+func (e *AlreadyAdmittedError) Error() string {
+	return fmt.Sprintf("seat(s) %s already admitted at %s by %s (%s)",
+		strings.Join(e.Seats, ", "), e.AdmittedAt.Format(time.RFC3339), e.Scanner, e.Cinema)
+}
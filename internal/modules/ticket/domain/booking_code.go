@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// bookingCodeAlphabet leaves out characters that are easily confused when
+// read out or typed: 0/O, 1/I/L.
+const bookingCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// bookingCodeLength random characters give about 50 bits of entropy, which
+// keeps codes unguessable at the gate.
+const bookingCodeLength = 10
+
+// NewBookingCode returns a random booking code such as "K7QF9-MZP3T".
+func NewBookingCode() (string, error) {
+	base := big.NewInt(int64(len(bookingCodeAlphabet)))
+	code := make([]byte, 0, bookingCodeLength+1)
+	for i := 0; i < bookingCodeLength; i++ {
+		if i == bookingCodeLength/2 {
+			code = append(code, '-')
+		}
+		n, err := rand.Int(rand.Reader, base)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate booking code: %w", err)
+		}
+		code = append(code, bookingCodeAlphabet[n.Int64()])
+	}
+	return string(code), nil
+}
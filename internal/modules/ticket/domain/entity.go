@@ -1,32 +1,145 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
 	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+var (
+	ErrTicketNotFound   = errors.New("ticket not found")
+	ErrInvalidSeat      = errors.New("invalid seat")
+	ErrSeatsUnavailable = errors.New("one or more seats are no longer available")
+	ErrShowtimeStarted  = errors.New("showtime has already started")
+	ErrNotCancellable   = errors.New("only paid, active tickets can be cancelled")
+	ErrBookingCodeTaken = errors.New("booking code already in use")
+)
+
+// Ticket statuses. A booking waits in pending_payment until its payment is
+// captured; if the payment fails its seats are released.
+const (
+	StatusPendingPayment = "pending_payment"
+	StatusPaymentFailed  = "payment_failed"
+	StatusActive         = "active"
+	StatusCompleted      = "completed"
+	StatusCancelled      = "cancelled"
 )
 
 type Ticket struct {
-	ID          int64             `gorm:"primaryKey" json:"id"`
-	UserID      int64             `gorm:"not null" json:"user_id"`
-	User        userDomain.User   `gorm:"foreignKey:UserID" json:"-"`
-	MovieID     int64             `gorm:"not null" json:"movie_id"`
-	Movie       movieDomain.Movie `gorm:"foreignKey:MovieID" json:"movie"`
-	ShowtimeID  int64             `gorm:"not null" json:"showtime_id"`
-	BookingCode string            `gorm:"type:varchar(20);unique;not null" json:"booking_code"` // For QR
-	Seats       string            `gorm:"type:varchar(50);not null" json:"seats"`               // e.g. "G14, G15"
-	CinemaName  string            `gorm:"type:varchar(100);not null" json:"cinema_name"`        // e.g. "AMC Empire 25"
-	TheaterName string            `gorm:"type:varchar(50);not null" json:"theater_name"`        // e.g. "Auditorium 12"
-	Price       float64           `gorm:"type:decimal(10,2);not null" json:"price"`
-	Status      string            `gorm:"type:varchar(20);default:'active'" json:"status"` // active, history, cancelled
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
+	ID           int64                `gorm:"primaryKey" json:"id"`
+	UserID       int64                `gorm:"not null" json:"user_id"`
+	User         userDomain.User      `gorm:"foreignKey:UserID" json:"-"`
+	MovieID      int64                `gorm:"not null" json:"movie_id"`
+	Movie        movieDomain.Movie    `gorm:"foreignKey:MovieID" json:"movie"`
+	ShowtimeID   int64                `gorm:"not null" json:"showtime_id"`
+	Showtime     movieDomain.Showtime `gorm:"foreignKey:ShowtimeID" json:"-"`
+	BookingCode  string               `gorm:"type:varchar(20);unique;not null" json:"booking_code"` // e.g. "K7QF9-MZP3T", see NewBookingCode
+	Seats        string               `gorm:"type:text;not null" json:"seats"`                      // e.g. "G14, G15", display only
+	SeatList     []TicketSeat         `gorm:"foreignKey:TicketID" json:"-"`
+	CinemaName   string               `gorm:"type:varchar(100);not null" json:"cinema_name"` // e.g. "AMC Empire 25"
+	TheaterName  string               `gorm:"type:varchar(50);not null" json:"theater_name"` // e.g. "Auditorium 12"
+	Price        float64              `gorm:"type:decimal(10,2);not null" json:"price"`
+	Status       string               `gorm:"type:varchar(20);default:'active'" json:"status"`
+	CancelReason string               `gorm:"type:varchar(255)" json:"cancel_reason,omitempty"` // Cancellation fields are set once cancelled
+	RefundAmount float64              `gorm:"type:decimal(10,2);not null;default:0" json:"refund_amount"`
+	CancelledAt  *time.Time           `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// TicketSeat is one seat of a ticket. The partial unique index makes it
+// impossible to book the same seat twice for a showtime unless the earlier
+// ticket was cancelled.
+type TicketSeat struct {
+	ID         int64  `gorm:"primaryKey" json:"id"`
+	TicketID   int64  `gorm:"not null;index" json:"ticket_id"`
+	ShowtimeID int64  `gorm:"not null;uniqueIndex:idx_ticket_seats_showtime_seat,where:cancelled = false" json:"showtime_id"`
+	Row        string `gorm:"type:varchar(5);not null;uniqueIndex:idx_ticket_seats_showtime_seat" json:"row"`
+	Number     int    `gorm:"not null;uniqueIndex:idx_ticket_seats_showtime_seat" json:"number"`
+	Cancelled  bool   `gorm:"not null;default:false" json:"cancelled"`
+	// Type and Price are what the seat was sold as; empty for seats backfilled
+	// from legacy tickets
+	Type  string  `gorm:"type:varchar(20)" json:"type"`
+	Price float64 `gorm:"type:decimal(10,2);not null;default:0" json:"price"`
+}
+
+// Label returns the seat as shown on the layout, e.g. "G14".
+func (s TicketSeat) Label() string {
+	return fmt.Sprintf("%s%d", s.Row, s.Number)
+}
+
+// ParseSeatLabel splits a seat label like "G14" into its row and number.
+func ParseSeatLabel(label string) (string, int, error) {
+	label = strings.ToUpper(strings.TrimSpace(label))
+	i := strings.IndexAny(label, "0123456789")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidSeat, label)
+	}
+	number, err := strconv.Atoi(label[i:])
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("%w: %s", ErrInvalidSeat, label)
+	}
+	return label[:i], number, nil
+}
+
+// RefundPolicy decides how much of a ticket's price is refunded depending on
+// how long before the showtime it is cancelled.
+type RefundPolicy struct {
+	FullRefundBefore     time.Duration // Full refund when cancelled at least this early
+	PartialRefundPercent float64       // Refunded in between, 0-100
+	NoRefundWithin       time.Duration // Nothing refunded this close to the start
+}
+
+// Refund returns the refund for a ticket of price cancelled untilStart before
+// the showtime, and the percentage it represents.
+func (p RefundPolicy) Refund(price float64, untilStart time.Duration) (float64, float64) {
+	percent := p.PartialRefundPercent
+	switch {
+	case untilStart >= p.FullRefundBefore:
+		percent = 100
+	case untilStart < p.NoRefundWithin:
+		percent = 0
+	}
+	return math.Round(price * percent / 100), percent
 }
 
 type TicketRepository interface {
 	GetByUserID(userID int64, status string) ([]Ticket, error)
+	// GetPageByUserID is GetByUserID paged after the encoded cursor, empty
+	// for the first page.
+	GetPageByUserID(userID int64, status, cursor string, limit int) ([]Ticket, pagination.Meta, error)
 	GetByID(id int64) (*Ticket, error)
+	GetByBookingCode(code string) (*Ticket, error)
 	GetBookedSeats(showtimeID int64) ([]string, error)
+	// GetUpcomingBookedSeats returns the distinct seats ("G14") booked for any
+	// showtime in the theater that has not started yet.
+	GetUpcomingBookedSeats(theaterID int64) ([]string, error)
 	Create(ticket *Ticket) error
+	// CreateBooking inserts the ticket and its SeatList only if none of the
+	// seats are already booked or held by another user, and consumes the
+	// buyer's own holds for the showtime. Returns ErrSeatsUnavailable otherwise,
+	// or ErrBookingCodeTaken if the ticket's booking code is not unique.
+	CreateBooking(ticket *Ticket) error
+	// Cancel cancels an active ticket and releases its seats, reporting
+	// whether the ticket was still active.
+	Cancel(ticket *Ticket) (bool, error)
+	// FailPayment marks a ticket still awaiting payment as payment_failed and
+	// releases its seats; tickets in any other status are left alone.
+	FailPayment(ticketID int64) error
+	// CompleteFinished marks active tickets whose showtime ended before now as
+	// completed and returns how many changed.
+	CompleteFinished(now time.Time) (int64, error)
+
+	GetAdmissions(ticketID int64) ([]Admission, error)
+	// Admit records the admissions, all or none. A seat admitted in the
+	// meantime yields an *AlreadyAdmittedError without details; callers
+	// reload the admissions to fill them in.
+	Admit(admissions []Admission) error
 }
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidQRPayload = errors.New("invalid or tampered QR payload")
+
+// qrPrefix versions the payload format.
+const qrPrefix = "RTX1"
+
+// QRPayload is what a ticket's QR code holds, so gate staff can check a
+// ticket against the showtime without looking it up.
+type QRPayload struct {
+	TicketID    int64    `json:"t"`
+	ShowtimeID  int64    `json:"s"`
+	Seats       []string `json:"st"`
+	BookingCode string   `json:"c"`
+}
+
+// QRSigner signs and verifies QR payloads with HMAC-SHA256. Encoded payloads
+// look like "RTX1.<base64url json>.<base64url mac>".
+type QRSigner struct {
+	Secret []byte
+}
+
+func NewQRSigner(secret string) *QRSigner {
+	return &QRSigner{Secret: []byte(secret)}
+}
+
+func (s *QRSigner) Sign(payload QRPayload) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	body := qrPrefix + "." + base64.RawURLEncoding.EncodeToString(data)
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(body)), nil
+}
+
+func (s *QRSigner) Verify(encoded string) (*QRPayload, error) {
+	parts := strings.Split(strings.TrimSpace(encoded), ".")
+	if len(parts) != 3 || parts[0] != qrPrefix {
+		return nil, ErrInvalidQRPayload
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(mac, s.mac(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidQRPayload
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidQRPayload
+	}
+	var payload QRPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, ErrInvalidQRPayload
+	}
+	return &payload, nil
+}
+
+// IsQRPayload tells a signed payload apart from a plain booking code.
+func IsQRPayload(scanned string) bool {
+	return strings.HasPrefix(strings.TrimSpace(scanned), qrPrefix+".")
+}
+
+func (s *QRSigner) mac(body string) []byte {
+	h := hmac.New(sha256.New, s.Secret)
+	h.Write([]byte(body))
+	return h.Sum(nil)
+}
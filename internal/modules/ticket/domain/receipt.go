@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"errors"
+	"math"
+)
+
+var ErrNoReceipt = errors.New("ticket has no completed payment")
+
+// ReceiptIssuer is the business printed on tax receipts. Ticket prices
+// include tax at TaxRate percent.
+type ReceiptIssuer struct {
+	Name    string
+	Address string
+	TaxID   string
+	TaxRate float64
+}
+
+// SplitTax splits a tax-inclusive amount into its net amount and the tax in it.
+func (i ReceiptIssuer) SplitTax(gross float64) (float64, float64) {
+	tax := math.Round(gross * i.TaxRate / (100 + i.TaxRate))
+	return gross - tax, tax
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoActiveHold = errors.New("no active seat hold for this showtime")
+)
+
+// SeatHold reserves a single seat for a user while they check out. A hold is
+// only meaningful until ExpiresAt; expired rows are removed by the sweeper.
+type SeatHold struct {
+	ID         int64     `gorm:"primaryKey" json:"id"`
+	ShowtimeID int64     `gorm:"not null;uniqueIndex:idx_seat_holds_showtime_seat" json:"showtime_id"`
+	Seat       string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_seat_holds_showtime_seat" json:"seat"` // e.g. "G14"
+	UserID     int64     `gorm:"not null;index" json:"user_id"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SeatHoldRepository interface {
+	GetActiveByShowtime(showtimeID int64) ([]SeatHold, error)
+	GetActiveByUser(userID, showtimeID int64) ([]SeatHold, error)
+	// HoldSeats replaces the user's holds for the showtime with the given
+	// seats. Returns ErrSeatsUnavailable if any seat is booked or held by someone else.
+	HoldSeats(userID, showtimeID int64, seats []string, expiresAt time.Time) ([]SeatHold, error)
+	Release(userID, showtimeID int64) error
+	DeleteExpired(now time.Time) (int64, error)
+}
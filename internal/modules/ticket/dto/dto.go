@@ -1,21 +1,25 @@
 package dto
 
 import (
+	"fmt"
 	"time"
 
+	paymentDto "github.com/geraldiaditya/ratix-backend/internal/modules/payment/dto"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 )
 
 type TicketListResponse struct {
 	Tickets []TicketResponse `json:"tickets"`
+	Cursor  *pagination.Meta `json:"cursor,omitempty"` // Set in cursor mode
 }
 
 type TicketResponse struct {
 	ID         int64     `json:"id"`
 	MovieTitle string    `json:"movie_title"`
 	PosterURL  string    `json:"poster_url"`
-	Date       time.Time `json:"date"` // Extracted from some logic or just CreatedAt? Ideally from Showtime
-	Time       string    `json:"time"` // Formatted time
+	Date       time.Time `json:"date"` // Showtime start in the cinema's time zone
+	Time       string    `json:"time"` // e.g. "19:30", local to the cinema
 	CinemaName string    `json:"cinema_name"`
 	IsActive   bool      `json:"is_active"`
 }
@@ -24,43 +28,177 @@ type TicketDetailResponse struct {
 	ID             int64   `json:"id"`
 	MovieTitle     string  `json:"movie_title"`
 	PosterURL      string  `json:"poster_url"`
-	Rating         string  `json:"rating"`    // e.g. "PG-13 | 2h 46m"
-	Score          string  `json:"score"`     // "93% Rotten Tomatoes"
-	DateTimeString string  `json:"date_time"` // "Saturday, November 16, 2024 at 7:30 PM"
+	Rating         string  `json:"rating"`    // e.g. "13+ | 2h 46m"
+	Score          string  `json:"score"`     // e.g. "8.9/10", empty when unrated
+	DateTimeString string  `json:"date_time"` // "Saturday, November 16, 2024 at 7:30 PM", local to the cinema
 	CinemaName     string  `json:"cinema_name"`
 	TheaterName    string  `json:"theater_name"`
 	Seats          string  `json:"seats"`
-	BookingCode    string  `json:"booking_code"` // QR content
+	BookingCode    string  `json:"booking_code"` // For manual entry at the gate
+	QRCodeURL      string  `json:"qr_code_url"`  // Signed QR code image, see GET /tickets/:id/qr.png
+	PDFURL         string  `json:"pdf_url"`      // Printable ticket, see GET /tickets/:id/pdf
 	Price          float64 `json:"price"`
+	// PriceBreakdown lists what each seat cost; empty for legacy tickets
+	PriceBreakdown []SeatPrice `json:"price_breakdown"`
 }
 
-func ToTicketResponse(t domain.Ticket) TicketResponse {
-	// Dummy date/time logic if Showtime relation isn't full populated
-	// Ideally we use t.Showtime.StartTime
+type SeatPrice struct {
+	Seat  string  `json:"seat"`
+	Type  string  `json:"type"`
+	Price float64 `json:"price"`
+}
+
+type CancelTicketRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type CancelTicketResponse struct {
+	TicketID      int64     `json:"ticket_id"`
+	Status        string    `json:"status"`
+	RefundAmount  float64   `json:"refund_amount"`
+	RefundPercent float64   `json:"refund_percent"`
+	CancelledAt   time.Time `json:"cancelled_at"`
+}
+
+type CreateBookingRequest struct {
+	Seats []string `json:"seats" validate:"required,min=1,max=10,dive,required"` // e.g. ["G14", "G15"]
+}
+
+type BookingResponse struct {
+	TicketID    int64        `json:"ticket_id"`
+	BookingCode string       `json:"booking_code"`
+	ShowtimeID  int64        `json:"showtime_id"`
+	Seats       []BookedSeat `json:"seats"`
+	TotalPrice  float64      `json:"total_price"`
+	Status      string       `json:"status"`
+	// Payment to complete at Payment.CheckoutURL before the ticket is active
+	Payment *paymentDto.PaymentResponse `json:"payment"`
+}
 
+type BookedSeat struct {
+	Seat         string   `json:"seat"`
+	Type         string   `json:"type"`
+	Price        float64  `json:"price"`
+	AppliedRules []string `json:"applied_rules"` // Price rules behind Price, in order
+}
+
+type SeatHoldRequest struct {
+	Seats []string `json:"seats" validate:"required,min=1,max=10,dive,required"`
+}
+
+type SeatHoldResponse struct {
+	ShowtimeID int64     `json:"showtime_id"`
+	Seats      []string  `json:"seats"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func ToSeatHoldResponse(showtimeID int64, holds []domain.SeatHold) *SeatHoldResponse {
+	resp := &SeatHoldResponse{ShowtimeID: showtimeID, Seats: make([]string, len(holds))}
+	for i, h := range holds {
+		resp.Seats[i] = h.Seat
+		// All seats of one selection share the same expiry
+		resp.ExpiresAt = h.ExpiresAt
+	}
+	return resp
+}
+
+// ToTicketResponse and ToTicketDetailResponse expect the ticket's Movie and
+// Showtime (with its Cinema and Theater) to be preloaded.
+func ToTicketResponse(t domain.Ticket) TicketResponse {
+	start := localStart(t)
 	return TicketResponse{
 		ID:         t.ID,
 		MovieTitle: t.Movie.Title,
 		PosterURL:  t.Movie.PosterURL,
-		Date:       t.CreatedAt, // Fallback
-		Time:       t.CreatedAt.Format("15:04"),
-		CinemaName: t.CinemaName,
-		IsActive:   t.Status == "active",
+		Date:       start,
+		Time:       start.Format("15:04"),
+		CinemaName: cinemaName(t),
+		IsActive:   t.Status == domain.StatusActive,
 	}
 }
 
 func ToTicketDetailResponse(t domain.Ticket) TicketDetailResponse {
+	var score string
+	if t.Movie.Rating > 0 {
+		score = fmt.Sprintf("%.1f/10", t.Movie.Rating)
+	}
+	rating := t.Movie.RuntimeLabel()
+	if t.Movie.AgeCertification != "" {
+		rating = t.Movie.AgeCertification + " | " + rating
+	}
+
 	return TicketDetailResponse{
 		ID:             t.ID,
 		MovieTitle:     t.Movie.Title,
 		PosterURL:      t.Movie.PosterURL,
-		Rating:         "PG-13 | 2h 30m",                                     // Dummy
-		Score:          "95% Rotten Tomatoes",                                // Dummy
-		DateTimeString: t.CreatedAt.Format("Monday, 02 Jan 2006 at 3:04 PM"), // Placeholder
-		CinemaName:     t.CinemaName,
-		TheaterName:    t.TheaterName,
+		Rating:         rating,
+		Score:          score,
+		DateTimeString: localStart(t).Format("Monday, January 2, 2006 at 3:04 PM"),
+		CinemaName:     cinemaName(t),
+		TheaterName:    theaterName(t),
 		Seats:          t.Seats,
 		BookingCode:    t.BookingCode,
+		QRCodeURL:      fmt.Sprintf("/tickets/%d/qr.png", t.ID),
+		PDFURL:         fmt.Sprintf("/tickets/%d/pdf", t.ID),
 		Price:          t.Price,
+		PriceBreakdown: toSeatPrices(t.SeatList),
+	}
+}
+
+// localStart is when the ticket's showtime starts, on the cinema's clock.
+func localStart(t domain.Ticket) time.Time {
+	return t.Showtime.StartTime.In(t.Showtime.Cinema.Location())
+}
+
+// cinemaName and theaterName prefer the current names over the ones copied
+// onto the ticket at booking, which legacy tickets may lack.
+func cinemaName(t domain.Ticket) string {
+	if t.Showtime.Cinema.Name != "" {
+		return t.Showtime.Cinema.Name
+	}
+	return t.CinemaName
+}
+
+func theaterName(t domain.Ticket) string {
+	if t.Showtime.Theater != nil {
+		return t.Showtime.Theater.Name
 	}
+	return t.TheaterName
+}
+
+func toSeatPrices(seats []domain.TicketSeat) []SeatPrice {
+	prices := []SeatPrice{}
+	for _, s := range seats {
+		// Backfilled seats carry no type or price of their own
+		if s.Cancelled || s.Type == "" {
+			continue
+		}
+		prices = append(prices, SeatPrice{Seat: s.Label(), Type: s.Type, Price: s.Price})
+	}
+	return prices
+}
+
+type CheckinRequest struct {
+	Code  string   `json:"code" validate:"required,max=1024"`     // Scanned QR payload or booking code typed in by staff
+	Seats []string `json:"seats" validate:"max=10,dive,required"` // Seats entering now; all remaining seats when empty
+}
+
+type CheckinResponse struct {
+	Status         string    `json:"status"` // "admitted"
+	TicketID       int64     `json:"ticket_id"`
+	BookingCode    string    `json:"booking_code"`
+	MovieTitle     string    `json:"movie_title"`
+	TheaterName    string    `json:"theater_name"`
+	ShowtimeStart  time.Time `json:"showtime_start"`
+	AdmittedSeats  []string  `json:"admitted_seats"`
+	RemainingSeats []string  `json:"remaining_seats"` // Seats of the ticket not admitted yet
+}
+
+// AlreadyAdmittedResponse is returned with 409 when a ticket is scanned again.
+type AlreadyAdmittedResponse struct {
+	Error           string    `json:"error"`
+	Seats           []string  `json:"seats"`
+	FirstAdmittedAt time.Time `json:"first_admitted_at"`
+	Scanner         string    `json:"scanner"`
+	Cinema          string    `json:"cinema"`
 }
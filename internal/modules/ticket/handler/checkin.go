@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// DeviceKeyHeader carries the key a gate scanner received on registration.
+const DeviceKeyHeader = "X-Device-Key"
+
+type CheckinHandler struct {
+	Service   *service.CheckinService
+	Validator *validator.Validate
+}
+
+func NewCheckinHandler(service *service.CheckinService, v *validator.Validate) *CheckinHandler {
+	return &CheckinHandler{Service: service, Validator: v}
+}
+
+// RegisterRoutes mounts the gate endpoint. Scanners authenticate with their
+// device key rather than a user token.
+func (h *CheckinHandler) RegisterRoutes(app *fiber.App) {
+	app.Post("/checkin", h.handleCheckIn)
+}
+
+func (h *CheckinHandler) handleCheckIn(c *fiber.Ctx) error {
+	var req dto.CheckinRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CheckIn(c.Get(DeviceKeyHeader), req)
+	if err != nil {
+		var admitted *domain.AlreadyAdmittedError
+		if errors.As(err, &admitted) {
+			return c.Status(fiber.StatusConflict).JSON(dto.AlreadyAdmittedResponse{
+				Error:           "already admitted",
+				Seats:           admitted.Seats,
+				FirstAdmittedAt: admitted.AdmittedAt,
+				Scanner:         admitted.Scanner,
+				Cinema:          admitted.Cinema,
+			})
+		}
+		return c.Status(checkinErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func checkinErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, cinemaDomain.ErrInvalidDeviceKey):
+		return fiber.StatusUnauthorized
+	case errors.Is(err, domain.ErrInvalidQRPayload), errors.Is(err, domain.ErrInvalidSeat):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrUnknownTicketCode), errors.Is(err, domain.ErrTicketNotFound),
+		errors.Is(err, movieDomain.ErrShowtimeNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrNotAdmissible), errors.Is(err, domain.ErrWrongCinema),
+		errors.Is(err, domain.ErrOutsideAdmissionWindow):
+		return fiber.StatusUnprocessableEntity
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
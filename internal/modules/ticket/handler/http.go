@@ -1,32 +1,60 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	paymentDomain "github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/service"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type TicketHandler struct {
-	Service *service.TicketService
+	Service   *service.TicketService
+	Validator *validator.Validate
 }
 
-func NewTicketHandler(service *service.TicketService) *TicketHandler {
-	return &TicketHandler{Service: service}
+func NewTicketHandler(service *service.TicketService, v *validator.Validate) *TicketHandler {
+	return &TicketHandler{Service: service, Validator: v}
 }
 
-func (h *TicketHandler) RegisterRoutes(app *fiber.App) {
-	tickets := app.Group("/tickets")
-	// TODO: Add Middleware to get UserID
+func (h *TicketHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	tickets := app.Group("/tickets", auth)
 	tickets.Get("/", h.handleGetMyTickets)
 	tickets.Get("/:id", h.handleGetTicketDetail)
+	tickets.Post("/:id/cancel", h.handleCancelTicket)
+	tickets.Get("/:id/qr.png", h.handleGetQRPNG)
+	tickets.Get("/:id/qr.svg", h.handleGetQRSVG)
+	tickets.Get("/:id/pdf", h.handleGetTicketPDF)
+	tickets.Get("/:id/receipt.pdf", h.handleGetReceiptPDF)
+
+	// Booking
+	app.Post("/showtimes/:id/bookings", auth, h.handleCreateBooking)
 }
 
 func (h *TicketHandler) handleGetMyTickets(c *fiber.Ctx) error {
-	// Mock UserID for now (should come from context/JWT)
-	userID := int64(1)
+	userID := middleware.GetUserID(c)
 
 	status := c.Query("status")
+	if c.Context().QueryArgs().Has("cursor") {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		resp, err := h.Service.GetMyTicketsByCursor(userID, status, c.Query("cursor"), limit)
+		if err != nil {
+			if errors.Is(err, pagination.ErrInvalidCursor) {
+				return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+			}
+			return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+		}
+		return c.JSON(resp)
+	}
+
 	resp, err := h.Service.GetMyTickets(userID, status)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
@@ -40,9 +68,158 @@ func (h *TicketHandler) handleGetTicketDetail(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
 	}
 
-	resp, err := h.Service.GetTicketDetail(id)
+	resp, err := h.Service.GetTicketDetail(id, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrTicketNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *TicketHandler) handleGetQRPNG(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	png, err := h.Service.RenderTicketQRPNG(id, middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(qrErrorStatus(err)).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(png)
+}
+
+func (h *TicketHandler) handleGetQRSVG(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	svg, err := h.Service.RenderTicketQRSVG(id, middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(qrErrorStatus(err)).SendString(err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "image/svg+xml")
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(svg)
+}
+
+func (h *TicketHandler) handleGetTicketPDF(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	pdf, err := h.Service.RenderTicketPDF(id, middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(qrErrorStatus(err)).SendString(err.Error())
+	}
+	return sendPDF(c, pdf, fmt.Sprintf("ticket-%d.pdf", id))
+}
+
+func (h *TicketHandler) handleGetReceiptPDF(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	pdf, err := h.Service.RenderReceiptPDF(id, middleware.GetUserID(c))
+	if err != nil {
+		if errors.Is(err, domain.ErrNoReceipt) {
+			return c.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		return c.Status(qrErrorStatus(err)).SendString(err.Error())
+	}
+	return sendPDF(c, pdf, fmt.Sprintf("receipt-%d.pdf", id))
+}
+
+func sendPDF(c *fiber.Ctx, pdf []byte, filename string) error {
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="%s"`, filename))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(pdf)
+}
+
+func qrErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTicketNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrNotAdmissible):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *TicketHandler) handleCancelTicket(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.CancelTicketRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.CancelTicket(id, middleware.GetUserID(c), req.Reason)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		return c.Status(cancelErrorStatus(err)).SendString(err.Error())
 	}
 	return c.JSON(resp)
 }
+
+func cancelErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrTicketNotFound), errors.Is(err, movieDomain.ErrShowtimeNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrNotCancellable), errors.Is(err, domain.ErrShowtimeStarted),
+		errors.Is(err, paymentDomain.ErrNotRefundable), errors.Is(err, paymentDomain.ErrRefundInProgress):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func (h *TicketHandler) handleCreateBooking(c *fiber.Ctx) error {
+	showtimeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.CreateBookingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+
+	resp, err := h.Service.CreateBooking(userID, showtimeID, req.Seats)
+	if err != nil {
+		return c.Status(bookingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func bookingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, movieDomain.ErrShowtimeNotFound), errors.Is(err, domain.ErrNoActiveHold):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidSeat):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrSeatsUnavailable), errors.Is(err, domain.ErrShowtimeStarted):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type SeatHoldHandler struct {
+	Service   *service.SeatHoldService
+	Validator *validator.Validate
+}
+
+func NewSeatHoldHandler(service *service.SeatHoldService, v *validator.Validate) *SeatHoldHandler {
+	return &SeatHoldHandler{Service: service, Validator: v}
+}
+
+func (h *SeatHoldHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	holds := app.Group("/showtimes/:id/holds", auth)
+	holds.Post("/", h.handleHoldSeats)
+	holds.Get("/", h.handleGetMyHold)
+	holds.Delete("/", h.handleRelease)
+	holds.Post("/checkout", h.handleCheckout)
+}
+
+func (h *SeatHoldHandler) handleHoldSeats(c *fiber.Ctx) error {
+	showtimeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.SeatHoldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	userID := middleware.GetUserID(c)
+
+	resp, err := h.Service.HoldSeats(userID, showtimeID, req.Seats)
+	if err != nil {
+		return c.Status(bookingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *SeatHoldHandler) handleGetMyHold(c *fiber.Ctx) error {
+	showtimeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	userID := middleware.GetUserID(c)
+
+	resp, err := h.Service.GetMyHold(userID, showtimeID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoActiveHold) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *SeatHoldHandler) handleRelease(c *fiber.Ctx) error {
+	showtimeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	userID := middleware.GetUserID(c)
+
+	if err := h.Service.Release(userID, showtimeID); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *SeatHoldHandler) handleCheckout(c *fiber.Ctx) error {
+	showtimeID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	userID := middleware.GetUserID(c)
+
+	resp, err := h.Service.Checkout(userID, showtimeID)
+	if err != nil {
+		return c.Status(bookingErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
@@ -1,13 +1,22 @@
 package repository
 
 import (
+	"database/sql"
 	"errors"
-	"fmt"
+	"strings"
+	"time"
 
 	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// maxBookingAttempts bounds how often a booking is retried after Postgres
+// aborts it with a serialization failure (two users racing for a seat).
+const maxBookingAttempts = 3
+
 type PostgresTicketRepository struct {
 	DB *gorm.DB
 }
@@ -16,33 +25,68 @@ func NewPostgresTicketRepository(db *gorm.DB) *PostgresTicketRepository {
 	return &PostgresTicketRepository{DB: db}
 }
 
-func (r *PostgresTicketRepository) GetByUserID(userID int64, status string) ([]domain.Ticket, error) {
-	var tickets []domain.Ticket
-	query := r.DB.Where("user_id = ?", userID).Preload("Movie")
+// ticketOrder lists recent bookings first.
+var ticketOrder = pagination.Order{Name: "booked", Key: "tickets.created_at", KeyType: "timestamptz", ID: "tickets.id", Desc: true}
+
+// userTickets selects userID's tickets in the status group: "history" for
+// watched or cancelled, any other non-empty value for active ones.
+func (r *PostgresTicketRepository) userTickets(userID int64, status string) *gorm.DB {
+	query := r.DB.Model(&domain.Ticket{}).Where("tickets.user_id = ?", userID)
 
 	if status != "" {
 		if status == "history" {
-			// History means watched or cancelled -> simplified for now, assuming 'history' status exists
-			// Or logic: status IN ('completed', 'cancelled')
-			query = query.Where("status IN ?", []string{"completed", "cancelled"})
+			// History means watched or cancelled
+			query = query.Where("tickets.status IN ?", []string{domain.StatusCompleted, domain.StatusCancelled})
 		} else {
-			// Active default
-			query = query.Where("status = ?", "active")
+			// Active default, including bookings still being paid for
+			query = query.Where("tickets.status IN ?", []string{domain.StatusActive, domain.StatusPendingPayment})
 		}
 	}
+	return query
+}
 
-	// Recent bookings first
-	if err := query.Order("created_at desc").Find(&tickets).Error; err != nil {
+func (r *PostgresTicketRepository) GetByUserID(userID int64, status string) ([]domain.Ticket, error) {
+	var tickets []domain.Ticket
+	query := r.userTickets(userID, status).Preload("Movie").Preload("Showtime.Cinema").Preload("Showtime.Theater")
+	if err := query.Order(ticketOrder.SQL()).Find(&tickets).Error; err != nil {
 		return nil, err
 	}
 	return tickets, nil
 }
 
+func (r *PostgresTicketRepository) GetPageByUserID(userID int64, status, cursor string, limit int) ([]domain.Ticket, pagination.Meta, error) {
+	after, err := pagination.Decode(cursor, ticketOrder)
+	if err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	ids, meta, err := pagination.Keyset(r.userTickets(userID, status), ticketOrder, after, limit)
+	if err != nil || len(ids) == 0 {
+		return nil, meta, err
+	}
+
+	var tickets []domain.Ticket
+	if err := r.DB.Preload("Movie").Preload("Showtime.Cinema").Preload("Showtime.Theater").Find(&tickets, ids).Error; err != nil {
+		return nil, pagination.Meta{}, err
+	}
+	return pagination.Arrange(tickets, ids, func(t domain.Ticket) int64 { return t.ID }), meta, nil
+}
+
 func (r *PostgresTicketRepository) GetByID(id int64) (*domain.Ticket, error) {
 	var ticket domain.Ticket
-	if err := r.DB.Preload("Movie").First(&ticket, id).Error; err != nil {
+	if err := r.DB.Preload("Movie").Preload("SeatList").Preload("Showtime.Cinema").Preload("Showtime.Theater").First(&ticket, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrTicketNotFound
+		}
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *PostgresTicketRepository) GetByBookingCode(code string) (*domain.Ticket, error) {
+	var ticket domain.Ticket
+	if err := r.DB.Preload("Movie").Preload("SeatList").Preload("Showtime.Cinema").Preload("Showtime.Theater").Where("booking_code = ?", code).First(&ticket).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("ticket not found")
+			return nil, domain.ErrTicketNotFound
 		}
 		return nil, err
 	}
@@ -53,26 +97,230 @@ func (r *PostgresTicketRepository) Create(ticket *domain.Ticket) error {
 	return r.DB.Create(ticket).Error
 }
 
+func (r *PostgresTicketRepository) CreateBooking(ticket *domain.Ticket) error {
+	seats := make([]string, len(ticket.SeatList))
+	for i, seat := range ticket.SeatList {
+		seats[i] = seat.Label()
+	}
+
+	var err error
+	for attempt := 0; attempt < maxBookingAttempts; attempt++ {
+		// A failed attempt may have assigned IDs before rolling back
+		ticket.ID = 0
+		for i := range ticket.SeatList {
+			ticket.SeatList[i].ID = 0
+			ticket.SeatList[i].ShowtimeID = ticket.ShowtimeID
+		}
+
+		err = r.DB.Transaction(func(tx *gorm.DB) error {
+			booked, err := bookedSeatSet(tx, ticket.ShowtimeID)
+			if err != nil {
+				return err
+			}
+			for _, seat := range seats {
+				if booked[seat] {
+					return domain.ErrSeatsUnavailable
+				}
+			}
+
+			// Seats held by other users during their checkout are off limits
+			var heldByOthers int64
+			if err := tx.Model(&domain.SeatHold{}).
+				Where("showtime_id = ? AND seat IN ? AND user_id != ? AND expires_at > ?", ticket.ShowtimeID, seats, ticket.UserID, time.Now()).
+				Count(&heldByOthers).Error; err != nil {
+				return err
+			}
+			if heldByOthers > 0 {
+				return domain.ErrSeatsUnavailable
+			}
+
+			// Creates the ticket_seats rows too; idx_ticket_seats_showtime_seat
+			// rejects any seat that slipped past the check above
+			if err := tx.Create(ticket).Error; err != nil {
+				return err
+			}
+			// The booking supersedes whatever the user was holding for this showtime
+			return tx.Where("user_id = ? AND showtime_id = ?", ticket.UserID, ticket.ShowtimeID).Delete(&domain.SeatHold{}).Error
+		}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+
+		if isUniqueViolation(err) {
+			if isBookingCodeViolation(err) {
+				return domain.ErrBookingCodeTaken
+			}
+			return domain.ErrSeatsUnavailable
+		}
+		if !isSerializationFailure(err) {
+			return err
+		}
+	}
+	// Still losing the race after retrying means someone else holds the seats
+	return domain.ErrSeatsUnavailable
+}
+
+// bookedSeatSet returns every seat ("G14") taken by a non-cancelled ticket
+// for the showtime.
+func bookedSeatSet(tx *gorm.DB, showtimeID int64) (map[string]bool, error) {
+	var seats []domain.TicketSeat
+	if err := tx.Where("showtime_id = ? AND cancelled = ?", showtimeID, false).Find(&seats).Error; err != nil {
+		return nil, err
+	}
+
+	booked := make(map[string]bool, len(seats))
+	for _, s := range seats {
+		booked[s.Label()] = true
+	}
+	return booked, nil
+}
+
+func isSerializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "40001"
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func isBookingCodeViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && strings.Contains(pgErr.ConstraintName, "booking_code")
+}
+
 func (r *PostgresTicketRepository) GetBookedSeats(showtimeID int64) ([]string, error) {
-	var seats []string
-	// Assuming "seats" column stores "A1, A2" (comma separated) or single seat "A1"
-	// We need to fetch all seats from active bookings.
-	// Since the DB stores a string, we might need to parse it if one ticket has multiple seats.
-	// However, usually specific impl might vary. For now, let's just fetch the strings.
-	var tickets []domain.Ticket
-	if err := r.DB.Where("showtime_id = ? AND status != ?", showtimeID, "cancelled").Find(&tickets).Error; err != nil {
+	booked, err := bookedSeatSet(r.DB, showtimeID)
+	if err != nil {
 		return nil, err
 	}
 
-	for _, t := range tickets {
-		// Normalize or split if needed. The Entity says `Seats string`.
-		// If it's "A1, A2", we should split.
-		// For simplicity in this iteration, we return the raw string and let service handle splitting or we do it here.
-		// I'll append raw strings, but splitting is safer if we want individual seats.
-		// Let's assume one ticket might hold multiple seats.
-		// We can leave splitting to the service layer or do it here.
-		// Let's return the simplified list of strings from DB for now.
-		seats = append(seats, t.Seats)
+	seats := make([]string, 0, len(booked))
+	for seat := range booked {
+		seats = append(seats, seat)
 	}
 	return seats, nil
 }
+
+func (r *PostgresTicketRepository) GetUpcomingBookedSeats(theaterID int64) ([]string, error) {
+	var seats []domain.TicketSeat
+	if err := r.DB.Distinct("ticket_seats.row", "ticket_seats.number").
+		Joins("JOIN showtimes ON showtimes.id = ticket_seats.showtime_id").
+		Where("showtimes.theater_id = ? AND showtimes.start_time > ? AND ticket_seats.cancelled = ?", theaterID, time.Now(), false).
+		Find(&seats).Error; err != nil {
+		return nil, err
+	}
+
+	labels := make([]string, len(seats))
+	for i, s := range seats {
+		labels[i] = s.Label()
+	}
+	return labels, nil
+}
+
+func (r *PostgresTicketRepository) Cancel(ticket *domain.Ticket) (bool, error) {
+	var cancelled bool
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Ticket{}).
+			Where("id = ? AND status = ?", ticket.ID, domain.StatusActive).
+			Updates(map[string]interface{}{
+				"status":        domain.StatusCancelled,
+				"cancel_reason": ticket.CancelReason,
+				"refund_amount": ticket.RefundAmount,
+				"cancelled_at":  ticket.CancelledAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cancelled = true
+
+		// Frees the seats: idx_ticket_seats_showtime_seat only covers live seats
+		return tx.Model(&domain.TicketSeat{}).
+			Where("ticket_id = ?", ticket.ID).
+			Update("cancelled", true).Error
+	})
+	return cancelled, err
+}
+
+func (r *PostgresTicketRepository) FailPayment(ticketID int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Ticket{}).
+			Where("id = ? AND status = ?", ticketID, domain.StatusPendingPayment).
+			Update("status", domain.StatusPaymentFailed)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&domain.TicketSeat{}).
+			Where("ticket_id = ?", ticketID).
+			Update("cancelled", true).Error
+	})
+}
+
+func (r *PostgresTicketRepository) CompleteFinished(now time.Time) (int64, error) {
+	// A showtime ends when its movie does
+	result := r.DB.Exec(`UPDATE tickets SET status = ?, updated_at = ?
+		FROM showtimes JOIN movies ON movies.id = showtimes.movie_id
+		WHERE tickets.showtime_id = showtimes.id AND tickets.status = ?
+		AND showtimes.start_time + movies.duration * interval '1 minute' < ?`,
+		domain.StatusCompleted, now, domain.StatusActive, now)
+	return result.RowsAffected, result.Error
+}
+
+// BackfillSeats creates ticket_seats rows for tickets booked before seats were
+// normalized, by parsing the legacy Seats string. Seats that cannot be parsed
+// or collide with an existing booking are skipped.
+func (r *PostgresTicketRepository) BackfillSeats() (created, skipped int64, err error) {
+	var tickets []domain.Ticket
+	if err := r.DB.Where("NOT EXISTS (SELECT 1 FROM ticket_seats WHERE ticket_seats.ticket_id = tickets.id)").
+		Find(&tickets).Error; err != nil {
+		return 0, 0, err
+	}
+
+	for _, t := range tickets {
+		for _, label := range strings.Split(t.Seats, ",") {
+			if strings.TrimSpace(label) == "" {
+				continue
+			}
+			row, number, err := domain.ParseSeatLabel(label)
+			if err != nil {
+				skipped++
+				continue
+			}
+
+			seat := domain.TicketSeat{
+				TicketID:   t.ID,
+				ShowtimeID: t.ShowtimeID,
+				Row:        row,
+				Number:     number,
+				Cancelled:  t.Status == "cancelled",
+			}
+			result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&seat)
+			if result.Error != nil {
+				return created, skipped, result.Error
+			}
+			if result.RowsAffected == 0 {
+				skipped++
+			} else {
+				created++
+			}
+		}
+	}
+	return created, skipped, nil
+}
+
+func (r *PostgresTicketRepository) GetAdmissions(ticketID int64) ([]domain.Admission, error) {
+	var admissions []domain.Admission
+	if err := r.DB.Where("ticket_id = ?", ticketID).Order("admitted_at").Find(&admissions).Error; err != nil {
+		return nil, err
+	}
+	return admissions, nil
+}
+
+func (r *PostgresTicketRepository) Admit(admissions []domain.Admission) error {
+	if err := r.DB.Create(&admissions).Error; err != nil {
+		if isUniqueViolation(err) {
+			// Another gate scanned the same ticket at the same moment
+			return &domain.AlreadyAdmittedError{}
+		}
+		return err
+	}
+	return nil
+}
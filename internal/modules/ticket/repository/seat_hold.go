@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"gorm.io/gorm"
+)
+
+type PostgresSeatHoldRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresSeatHoldRepository(db *gorm.DB) *PostgresSeatHoldRepository {
+	return &PostgresSeatHoldRepository{DB: db}
+}
+
+func (r *PostgresSeatHoldRepository) GetActiveByShowtime(showtimeID int64) ([]domain.SeatHold, error) {
+	var holds []domain.SeatHold
+	if err := r.DB.Where("showtime_id = ? AND expires_at > ?", showtimeID, time.Now()).Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *PostgresSeatHoldRepository) GetActiveByUser(userID, showtimeID int64) ([]domain.SeatHold, error) {
+	var holds []domain.SeatHold
+	if err := r.DB.Where("user_id = ? AND showtime_id = ? AND expires_at > ?", userID, showtimeID, time.Now()).
+		Order("seat").
+		Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *PostgresSeatHoldRepository) HoldSeats(userID, showtimeID int64, seats []string, expiresAt time.Time) ([]domain.SeatHold, error) {
+	holds := make([]domain.SeatHold, len(seats))
+	for i, seat := range seats {
+		holds[i] = domain.SeatHold{ShowtimeID: showtimeID, Seat: seat, UserID: userID, ExpiresAt: expiresAt}
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Expired holds still occupy the unique index until the sweeper runs
+		if err := tx.Where("showtime_id = ? AND expires_at <= ?", showtimeID, now).Delete(&domain.SeatHold{}).Error; err != nil {
+			return err
+		}
+
+		booked, err := bookedSeatSet(tx, showtimeID)
+		if err != nil {
+			return err
+		}
+		for _, seat := range seats {
+			if booked[seat] {
+				return domain.ErrSeatsUnavailable
+			}
+		}
+
+		// Replace the user's previous selection for this showtime
+		if err := tx.Where("user_id = ? AND showtime_id = ?", userID, showtimeID).Delete(&domain.SeatHold{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&holds).Error
+	})
+	if err != nil {
+		// Another user grabbed one of the seats between our check and insert
+		if isUniqueViolation(err) {
+			return nil, domain.ErrSeatsUnavailable
+		}
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *PostgresSeatHoldRepository) Release(userID, showtimeID int64) error {
+	return r.DB.Where("user_id = ? AND showtime_id = ?", userID, showtimeID).Delete(&domain.SeatHold{}).Error
+}
+
+func (r *PostgresSeatHoldRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.DB.Where("expires_at <= ?", now).Delete(&domain.SeatHold{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cinemaService "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/service"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+)
+
+type CheckinService struct {
+	Repo          domain.TicketRepository
+	MovieRepo     movieDomain.MovieRepository
+	CinemaService *cinemaService.CinemaService
+	QRSigner      *domain.QRSigner
+	Window        domain.AdmissionWindow
+}
+
+func NewCheckinService(repo domain.TicketRepository, movieRepo movieDomain.MovieRepository, cinemaService *cinemaService.CinemaService, qrSigner *domain.QRSigner, window domain.AdmissionWindow) *CheckinService {
+	return &CheckinService{Repo: repo, MovieRepo: movieRepo, CinemaService: cinemaService, QRSigner: qrSigner, Window: window}
+}
+
+// CheckIn admits a ticket at the gate of the scanner identified by deviceKey.
+// Groups may enter in parts by naming the seats entering now; a seat can only
+// ever be admitted once.
+func (s *CheckinService) CheckIn(deviceKey string, req dto.CheckinRequest) (*dto.CheckinResponse, error) {
+	scanner, err := s.CinemaService.AuthenticateScanner(deviceKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.resolveTicket(req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status != domain.StatusActive {
+		return nil, fmt.Errorf("%w: ticket is %s", domain.ErrNotAdmissible, ticket.Status)
+	}
+
+	showtime, err := s.MovieRepo.GetShowtimeByID(ticket.ShowtimeID)
+	if err != nil {
+		return nil, err
+	}
+	if showtime.CinemaID != scanner.CinemaID {
+		return nil, domain.ErrWrongCinema
+	}
+	now := time.Now()
+	if !s.Window.Contains(showtime.StartTime, now) {
+		loc := showtime.Cinema.Location()
+		return nil, fmt.Errorf("%w: admission runs from %s to %s", domain.ErrOutsideAdmissionWindow,
+			showtime.StartTime.Add(-s.Window.OpensBefore).In(loc).Format(time.RFC3339),
+			showtime.StartTime.Add(s.Window.ClosesAfter).In(loc).Format(time.RFC3339))
+	}
+
+	admissions, err := s.Repo.GetAdmissions(ticket.ID)
+	if err != nil {
+		return nil, err
+	}
+	admitted := make(map[int64]domain.Admission, len(admissions))
+	for _, a := range admissions {
+		admitted[a.TicketSeatID] = a
+	}
+
+	bySeat := make(map[string]domain.TicketSeat)
+	var remaining []domain.TicketSeat
+	for _, seat := range ticket.SeatList {
+		if seat.Cancelled {
+			continue
+		}
+		bySeat[seat.Label()] = seat
+		if _, ok := admitted[seat.ID]; !ok {
+			remaining = append(remaining, seat)
+		}
+	}
+	if len(bySeat) == 0 {
+		return nil, fmt.Errorf("%w: ticket has no seats", domain.ErrNotAdmissible)
+	}
+
+	entering := remaining
+	if len(req.Seats) > 0 {
+		var repeated []domain.Admission
+		var repeatedSeats []string
+		entering, repeated, repeatedSeats, err = requestedSeats(req.Seats, bySeat, admitted)
+		if err != nil {
+			return nil, err
+		}
+		if len(repeated) > 0 {
+			return nil, s.alreadyAdmitted(repeatedSeats, repeated)
+		}
+	} else if len(entering) == 0 {
+		var seats []string
+		for _, seat := range ticket.SeatList {
+			if !seat.Cancelled {
+				seats = append(seats, seat.Label())
+			}
+		}
+		return nil, s.alreadyAdmitted(seats, admissions)
+	}
+
+	records := make([]domain.Admission, len(entering))
+	for i, seat := range entering {
+		records[i] = domain.Admission{
+			TicketID:     ticket.ID,
+			TicketSeatID: seat.ID,
+			ShowtimeID:   ticket.ShowtimeID,
+			CinemaID:     scanner.CinemaID,
+			ScannerID:    scanner.ID,
+			AdmittedAt:   now,
+		}
+	}
+	if err := s.Repo.Admit(records); err != nil {
+		var raced *domain.AlreadyAdmittedError
+		if errors.As(err, &raced) {
+			// Another gate let seats in meanwhile; report them like any repeat
+			return nil, s.admittedMeanwhile(ticket.ID, entering, raced)
+		}
+		return nil, err
+	}
+
+	resp := &dto.CheckinResponse{
+		Status:         "admitted",
+		TicketID:       ticket.ID,
+		BookingCode:    ticket.BookingCode,
+		MovieTitle:     ticket.Movie.Title,
+		TheaterName:    ticket.TheaterName,
+		ShowtimeStart:  showtime.StartTime.In(showtime.Cinema.Location()),
+		AdmittedSeats:  []string{},
+		RemainingSeats: []string{},
+	}
+	done := make(map[int64]bool, len(entering))
+	for _, seat := range entering {
+		done[seat.ID] = true
+		resp.AdmittedSeats = append(resp.AdmittedSeats, seat.Label())
+	}
+	for _, seat := range remaining {
+		if !done[seat.ID] {
+			resp.RemainingSeats = append(resp.RemainingSeats, seat.Label())
+		}
+	}
+	return resp, nil
+}
+
+// resolveTicket accepts either a signed QR payload or a booking code.
+func (s *CheckinService) resolveTicket(code string) (*domain.Ticket, error) {
+	code = strings.TrimSpace(code)
+	if domain.IsQRPayload(code) {
+		payload, err := s.QRSigner.Verify(code)
+		if err != nil {
+			return nil, err
+		}
+		ticket, err := s.Repo.GetByID(payload.TicketID)
+		if err != nil {
+			return nil, err
+		}
+		// A reissued booking code invalidates QR codes printed before it
+		if ticket.BookingCode != payload.BookingCode || ticket.ShowtimeID != payload.ShowtimeID {
+			return nil, domain.ErrInvalidQRPayload
+		}
+		return ticket, nil
+	}
+
+	ticket, err := s.Repo.GetByBookingCode(strings.ToUpper(code))
+	if err != nil {
+		if errors.Is(err, domain.ErrTicketNotFound) {
+			return nil, domain.ErrUnknownTicketCode
+		}
+		return nil, err
+	}
+	return ticket, nil
+}
+
+// requestedSeats resolves the seat labels named at the gate against the
+// ticket's seats. A seat named twice enters once; seats admitted before are
+// returned as repeats with their admissions.
+func requestedSeats(labels []string, bySeat map[string]domain.TicketSeat, admitted map[int64]domain.Admission) (entering []domain.TicketSeat, repeated []domain.Admission, repeatedSeats []string, err error) {
+	named := make(map[int64]bool, len(labels))
+	for _, label := range labels {
+		row, number, err := domain.ParseSeatLabel(label)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		seat, ok := bySeat[fmt.Sprintf("%s%d", row, number)]
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: %s is not on this ticket", domain.ErrInvalidSeat, label)
+		}
+		if named[seat.ID] {
+			continue
+		}
+		named[seat.ID] = true
+		if a, ok := admitted[seat.ID]; ok {
+			repeated = append(repeated, a)
+			repeatedSeats = append(repeatedSeats, seat.Label())
+			continue
+		}
+		entering = append(entering, seat)
+	}
+	return entering, repeated, repeatedSeats, nil
+}
+
+// admittedMeanwhile reports which of the entering seats another gate admitted
+// since the ticket's admissions were loaded. Falls back to raced when they
+// cannot be found.
+func (s *CheckinService) admittedMeanwhile(ticketID int64, entering []domain.TicketSeat, raced *domain.AlreadyAdmittedError) error {
+	admissions, err := s.Repo.GetAdmissions(ticketID)
+	if err != nil {
+		return err
+	}
+	labels := make(map[int64]string, len(entering))
+	for _, seat := range entering {
+		labels[seat.ID] = seat.Label()
+	}
+
+	var seats []string
+	var repeated []domain.Admission
+	for _, a := range admissions {
+		if label, ok := labels[a.TicketSeatID]; ok {
+			seats = append(seats, label)
+			repeated = append(repeated, a)
+		}
+	}
+	if len(repeated) == 0 {
+		return raced
+	}
+	return s.alreadyAdmitted(seats, repeated)
+}
+
+// alreadyAdmitted reports the first admission among admissions, naming the
+// scanner and cinema that let the seats in.
+func (s *CheckinService) alreadyAdmitted(seats []string, admissions []domain.Admission) error {
+	first := admissions[0]
+	for _, a := range admissions[1:] {
+		if a.AdmittedAt.Before(first.AdmittedAt) {
+			first = a
+		}
+	}
+
+	e := &domain.AlreadyAdmittedError{Seats: seats, AdmittedAt: first.AdmittedAt}
+	if scanner, err := s.CinemaService.GetScanner(first.ScannerID); err == nil {
+		e.Scanner = scanner.Name
+	}
+	if cinema, err := s.CinemaService.GetCinema(first.CinemaID); err == nil {
+		e.Cinema = cinema.Name
+	}
+	return e
+}
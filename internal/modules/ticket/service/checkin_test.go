@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+)
+
+func TestRequestedSeatsRepeatedSeatEntersOnce(t *testing.T) {
+	bySeat := map[string]domain.TicketSeat{
+		"G14": {ID: 1, Row: "G", Number: 14},
+		"G15": {ID: 2, Row: "G", Number: 15},
+	}
+
+	entering, repeated, _, err := requestedSeats([]string{"G14", "g14", " G14 "}, bySeat, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repeated) != 0 {
+		t.Fatalf("repeated = %v, want none", repeated)
+	}
+	if len(entering) != 1 || entering[0].ID != 1 {
+		t.Fatalf("entering = %v, want only G14", entering)
+	}
+}
+
+func TestRequestedSeatsAdmittedSeatNamedTwice(t *testing.T) {
+	bySeat := map[string]domain.TicketSeat{"G14": {ID: 1, Row: "G", Number: 14}}
+	admitted := map[int64]domain.Admission{1: {TicketSeatID: 1, AdmittedAt: time.Now()}}
+
+	entering, repeated, seats, err := requestedSeats([]string{"G14", "G14"}, bySeat, admitted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entering) != 0 {
+		t.Fatalf("entering = %v, want none", entering)
+	}
+	if len(repeated) != 1 || len(seats) != 1 || seats[0] != "G14" {
+		t.Fatalf("repeated = %v %v, want G14 once", repeated, seats)
+	}
+}
+
+func TestRequestedSeatsUnknownSeat(t *testing.T) {
+	bySeat := map[string]domain.TicketSeat{"G14": {ID: 1, Row: "G", Number: 14}}
+
+	if _, _, _, err := requestedSeats([]string{"G14", "H1"}, bySeat, nil); !errors.Is(err, domain.ErrInvalidSeat) {
+		t.Fatalf("err = %v, want ErrInvalidSeat", err)
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	paymentDomain "github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// RenderTicketPDF renders one of userID's tickets as a printable e-ticket with
+// the same details and QR code the app shows. Like the QR code, it is only
+// available for active tickets.
+func (s *TicketService) RenderTicketPDF(id, userID int64) ([]byte, error) {
+	ticket, err := s.ownTicket(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	detail := dto.ToTicketDetailResponse(*ticket)
+	payload, err := s.ticketQRPayload(ticket)
+	if err != nil {
+		return nil, err
+	}
+	qr, err := qrcode.Encode(payload, qrcode.Medium, qrSize)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := newDocument("E-Ticket " + detail.BookingCode)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.CellFormat(120, 10, "RATIX E-TICKET", "", 0, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 10, "Booking code "+detail.BookingCode, "", 1, "R", false, 0, "")
+	pdf.Ln(4)
+
+	// Poster on the left, showtime details next to it. Posters are downloaded
+	// in the background; until the movie's current one is, the box is left out
+	top := pdf.GetY()
+	textX := 15.0
+	poster, err := s.MovieRepo.GetPoster(ticket.MovieID)
+	if err != nil && !errors.Is(err, movieDomain.ErrPosterNotFound) {
+		return nil, err
+	}
+	if poster != nil && poster.URL == ticket.Movie.PosterURL {
+		options := gofpdf.ImageOptions{ImageType: poster.ImageType}
+		pdf.RegisterImageOptionsReader("poster", options, bytes.NewReader(poster.Image))
+		if pdf.Ok() {
+			pdf.ImageOptions("poster", 15, top, 40, 60, false, options, 0, "")
+			textX = 62
+		} else {
+			// An undecodable poster must not fail the ticket
+			pdf.ClearError()
+		}
+	}
+
+	pdf.SetXY(textX, top)
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.MultiCell(0, 8, tr(detail.MovieTitle), "", "L", false)
+	pdf.SetX(textX)
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, tr(detail.Rating), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+	for _, row := range [][2]string{
+		{"Cinema", detail.CinemaName},
+		{"Theater", detail.TheaterName},
+		{"Showtime", detail.DateTimeString},
+		{"Seats", detail.Seats},
+	} {
+		pdf.SetX(textX)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(25, 7, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(0, 7, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.SetY(math.Max(pdf.GetY(), top+60) + 8)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(0, 8, "Price", "B", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, seat := range detail.PriceBreakdown {
+		pdf.CellFormat(40, 7, "Seat "+seat.Seat, "", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 7, seat.Type, "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, formatAmount(s.Payments.Currency, seat.Price), "", 1, "R", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(100, 8, "Total", "T", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, formatAmount(s.Payments.Currency, detail.Price), "T", 1, "R", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", 75, pdf.GetY(), 60, 60, true, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "Show this code at the gate. Booking code "+detail.BookingCode, "", 1, "C", false, 0, "")
+
+	return outputDocument(pdf)
+}
+
+// RenderReceiptPDF renders the tax receipt for the payment of one of userID's
+// tickets. Only payments that went through have a receipt; refunds are shown
+// on it.
+func (s *TicketService) RenderReceiptPDF(id, userID int64) ([]byte, error) {
+	ticket, err := s.ownTicket(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	detail := dto.ToTicketDetailResponse(*ticket)
+	payment, err := s.Payments.GetTicketPayment(id)
+	if err != nil {
+		if errors.Is(err, paymentDomain.ErrPaymentNotFound) {
+			return nil, domain.ErrNoReceipt
+		}
+		return nil, err
+	}
+	// A refund still with the provider is not on the receipt yet
+	if payment.Status != paymentDomain.StatusCaptured && payment.Status != paymentDomain.StatusRefunding && payment.Status != paymentDomain.StatusRefunded {
+		return nil, domain.ErrNoReceipt
+	}
+
+	pdf := newDocument(fmt.Sprintf("Receipt R-%08d", payment.ID))
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	issuer := s.ReceiptIssuer
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 8, tr(issuer.Name), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	if issuer.Address != "" {
+		pdf.MultiCell(0, 5, tr(issuer.Address), "", "L", false)
+	}
+	if issuer.TaxID != "" {
+		pdf.CellFormat(0, 5, "Tax ID "+issuer.TaxID, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(0, 10, "TAX RECEIPT", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	for _, row := range [][2]string{
+		{"Receipt no.", fmt.Sprintf("R-%08d", payment.ID)},
+		{"Paid on", paidAt(payment).In(ticket.Showtime.Cinema.Location()).Format("02 Jan 2006 15:04 MST")},
+		{"Booking code", detail.BookingCode},
+		{"Payment", paymentMethod(payment)},
+	} {
+		pdf.CellFormat(35, 6, row[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(130, 8, "Description", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 8, "Amount", "B", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	lines := detail.PriceBreakdown
+	if len(lines) == 0 {
+		lines = []dto.SeatPrice{{Seat: detail.Seats, Price: detail.Price}}
+	}
+	for _, line := range lines {
+		desc := fmt.Sprintf("%s, seat %s", detail.MovieTitle, line.Seat)
+		if line.Type != "" {
+			desc += " (" + line.Type + ")"
+		}
+		pdf.CellFormat(130, 7, tr(desc), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, formatAmount(payment.Currency, line.Price), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	net, tax := issuer.SplitTax(payment.Amount)
+	totals := [][2]string{
+		{"Subtotal (excl. tax)", formatAmount(payment.Currency, net)},
+		{fmt.Sprintf("Tax %g%%", issuer.TaxRate), formatAmount(payment.Currency, tax)},
+		{"Total paid", formatAmount(payment.Currency, payment.Amount)},
+	}
+	if payment.Status == paymentDomain.StatusRefunded && payment.RefundedAmount > 0 {
+		totals = append(totals, [2]string{"Refunded", "-" + formatAmount(payment.Currency, payment.RefundedAmount)})
+	}
+	for i, row := range totals {
+		border := ""
+		if i == 0 {
+			border = "T"
+		}
+		pdf.CellFormat(130, 7, row[0], border, 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, row[1], border, 1, "R", false, 0, "")
+	}
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Ticket prices include %g%% tax. %s, %s.",
+		issuer.TaxRate, tr(detail.CinemaName), tr(detail.DateTimeString)), "", "L", false)
+
+	return outputDocument(pdf)
+}
+
+func newDocument(title string) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+	pdf.SetCreator("Ratix", true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+	return pdf
+}
+
+func outputDocument(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// paidAt is when the payment was captured. Payments captured before that was
+// recorded fall back to when they were started, which is at most the payment
+// timeout earlier.
+func paidAt(p *paymentDomain.Payment) time.Time {
+	if p.CapturedAt != nil {
+		return *p.CapturedAt
+	}
+	return p.CreatedAt
+}
+
+func paymentMethod(p *paymentDomain.Payment) string {
+	if p.ProviderRef == nil {
+		return p.Provider
+	}
+	return fmt.Sprintf("%s (%s)", p.Provider, *p.ProviderRef)
+}
+
+// formatAmount prints whole currency units with thousands separators, e.g.
+// "IDR 50,000".
+func formatAmount(currency string, amount float64) string {
+	digits := fmt.Sprintf("%.0f", math.Abs(amount))
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	if amount < 0 {
+		return currency + " -" + b.String()
+	}
+	return currency + " " + b.String()
+}
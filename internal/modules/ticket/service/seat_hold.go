@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	cinemaService "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/service"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+)
+
+type SeatHoldService struct {
+	Repo          domain.SeatHoldRepository
+	CinemaService *cinemaService.CinemaService
+	TicketService *TicketService
+	TTL           time.Duration
+}
+
+func NewSeatHoldService(repo domain.SeatHoldRepository, cinemaService *cinemaService.CinemaService, ticketService *TicketService, ttl time.Duration) *SeatHoldService {
+	return &SeatHoldService{Repo: repo, CinemaService: cinemaService, TicketService: ticketService, TTL: ttl}
+}
+
+// HoldSeats reserves the seats for the user for TTL, replacing any seats the
+// user was already holding for the showtime. Showtimes that have started
+// cannot be held.
+func (s *SeatHoldService) HoldSeats(userID, showtimeID int64, seats []string) (*dto.SeatHoldResponse, error) {
+	if _, err := s.TicketService.bookableShowtime(showtimeID); err != nil {
+		return nil, err
+	}
+	layout, err := s.CinemaService.GetSeatLayout(showtimeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, _, _, err := resolveSeats(layout, seats)
+	if err != nil {
+		return nil, err
+	}
+
+	holds, err := s.Repo.HoldSeats(userID, showtimeID, labels, time.Now().Add(s.TTL))
+	if err != nil {
+		return nil, err
+	}
+	return dto.ToSeatHoldResponse(showtimeID, holds), nil
+}
+
+func (s *SeatHoldService) GetMyHold(userID, showtimeID int64) (*dto.SeatHoldResponse, error) {
+	holds, err := s.Repo.GetActiveByUser(userID, showtimeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, domain.ErrNoActiveHold
+	}
+	return dto.ToSeatHoldResponse(showtimeID, holds), nil
+}
+
+func (s *SeatHoldService) Release(userID, showtimeID int64) error {
+	return s.Repo.Release(userID, showtimeID)
+}
+
+// Checkout turns the user's active holds for the showtime into a ticket.
+func (s *SeatHoldService) Checkout(userID, showtimeID int64) (*dto.BookingResponse, error) {
+	holds, err := s.Repo.GetActiveByUser(userID, showtimeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, domain.ErrNoActiveHold
+	}
+
+	seats := make([]string, len(holds))
+	for i, h := range holds {
+		seats[i] = h.Seat
+	}
+	return s.TicketService.CreateBooking(userID, showtimeID, seats)
+}
+
+// StartSweeper periodically deletes expired holds until ctx is cancelled.
+// Reads already ignore expired holds, so the sweeper only keeps the table small.
+func (s *SeatHoldService) StartSweeper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				n, err := s.Repo.DeleteExpired(now)
+				if err != nil {
+					log.Printf("Warning: Failed to sweep expired seat holds: %v", err)
+				} else if n > 0 {
+					log.Printf("Released %d expired seat holds", n)
+				}
+			}
+		}
+	}()
+}
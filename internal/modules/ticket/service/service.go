@@ -1,16 +1,34 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	cinemaDto "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/dto"
+	cinemaService "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/service"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	paymentDomain "github.com/geraldiaditya/ratix-backend/internal/modules/payment/domain"
+	paymentService "github.com/geraldiaditya/ratix-backend/internal/modules/payment/service"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/ticket/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
 )
 
 type TicketService struct {
-	Repo domain.TicketRepository
+	Repo          domain.TicketRepository
+	MovieRepo     movieDomain.MovieRepository
+	CinemaService *cinemaService.CinemaService
+	Payments      *paymentService.PaymentService
+	RefundPolicy  domain.RefundPolicy
+	QRSigner      *domain.QRSigner
+	ReceiptIssuer domain.ReceiptIssuer
 }
 
-func NewTicketService(repo domain.TicketRepository) *TicketService {
-	return &TicketService{Repo: repo}
+func NewTicketService(repo domain.TicketRepository, movieRepo movieDomain.MovieRepository, cinemaService *cinemaService.CinemaService, payments *paymentService.PaymentService, refundPolicy domain.RefundPolicy, qrSigner *domain.QRSigner, receiptIssuer domain.ReceiptIssuer) *TicketService {
+	return &TicketService{Repo: repo, MovieRepo: movieRepo, CinemaService: cinemaService, Payments: payments, RefundPolicy: refundPolicy, QRSigner: qrSigner, ReceiptIssuer: receiptIssuer}
 }
 
 func (s *TicketService) GetMyTickets(userID int64, status string) (*dto.TicketListResponse, error) {
@@ -27,8 +45,25 @@ func (s *TicketService) GetMyTickets(userID int64, status string) (*dto.TicketLi
 	return &dto.TicketListResponse{Tickets: ticketResps}, nil
 }
 
-func (s *TicketService) GetTicketDetail(id int64) (*dto.TicketDetailResponse, error) {
-	ticket, err := s.Repo.GetByID(id)
+// GetMyTicketsByCursor is GetMyTickets a page at a time, after the encoded
+// cursor.
+func (s *TicketService) GetMyTicketsByCursor(userID int64, status, cursor string, limit int) (*dto.TicketListResponse, error) {
+	tickets, meta, err := s.Repo.GetPageByUserID(userID, status, cursor, pagination.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	ticketResps := []dto.TicketResponse{}
+	for _, t := range tickets {
+		ticketResps = append(ticketResps, dto.ToTicketResponse(t))
+	}
+	return &dto.TicketListResponse{Tickets: ticketResps, Cursor: &meta}, nil
+}
+
+// GetTicketDetail returns the ticket only if it belongs to userID, so other
+// users' tickets are indistinguishable from missing ones.
+func (s *TicketService) GetTicketDetail(id, userID int64) (*dto.TicketDetailResponse, error) {
+	ticket, err := s.ownTicket(id, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -36,3 +71,225 @@ func (s *TicketService) GetTicketDetail(id int64) (*dto.TicketDetailResponse, er
 	resp := dto.ToTicketDetailResponse(*ticket)
 	return &resp, nil
 }
+
+// ownTicket loads the ticket, or ErrTicketNotFound if it is not userID's.
+func (s *TicketService) ownTicket(id, userID int64) (*domain.Ticket, error) {
+	ticket, err := s.Repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.UserID != userID {
+		return nil, domain.ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// CancelTicket cancels one of userID's active tickets before its showtime
+// starts, refunds it according to the RefundPolicy and puts its seats back on
+// sale.
+func (s *TicketService) CancelTicket(id, userID int64, reason string) (*dto.CancelTicketResponse, error) {
+	ticket, err := s.ownTicket(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket.Status != domain.StatusActive {
+		return nil, domain.ErrNotCancellable
+	}
+
+	showtime, err := s.MovieRepo.GetShowtimeByID(ticket.ShowtimeID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	untilStart := showtime.StartTime.Sub(now)
+	if untilStart <= 0 {
+		return nil, domain.ErrShowtimeStarted
+	}
+
+	refund, percent := s.RefundPolicy.Refund(ticket.Price, untilStart)
+	// Refund first: a failed refund leaves the ticket active and the request
+	// can simply be retried, since repeating a completed refund is a no-op
+	if ticket.Price > 0 {
+		refunded, err := s.Payments.RefundTicket(ticket.ID, refund)
+		switch {
+		case errors.Is(err, paymentDomain.ErrPaymentNotFound):
+			// Seeded and legacy tickets were paid outside the payment module,
+			// so there is nothing to refund through it
+			refunded = 0
+		case err != nil:
+			return nil, err
+		}
+		// A retry may fall in another tier of the policy than the refund
+		// that already went through; the ticket records what was refunded
+		if refunded != refund {
+			refund = refunded
+			percent = math.Round(refund / ticket.Price * 100)
+		}
+	}
+
+	ticket.CancelReason = reason
+	ticket.RefundAmount = refund
+	ticket.CancelledAt = &now
+	cancelled, err := s.Repo.Cancel(ticket)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, domain.ErrNotCancellable
+	}
+
+	return &dto.CancelTicketResponse{
+		TicketID:      ticket.ID,
+		Status:        domain.StatusCancelled,
+		RefundAmount:  refund,
+		RefundPercent: percent,
+		CancelledAt:   now,
+	}, nil
+}
+
+// CreateBooking books the requested seats ("G14") for a showtime. Seats are
+// validated and priced against the same layout served by GET /showtimes/:id/seats,
+// and the final availability check happens inside the repository transaction.
+// The ticket stays pending_payment until the returned payment is captured.
+func (s *TicketService) CreateBooking(userID, showtimeID int64, seats []string) (*dto.BookingResponse, error) {
+	showtime, err := s.bookableShowtime(showtimeID)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := s.CinemaService.GetSeatLayout(showtimeID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, booked, total, err := resolveSeats(layout, seats)
+	if err != nil {
+		return nil, err
+	}
+
+	seatList := make([]domain.TicketSeat, len(labels))
+	for i, label := range labels {
+		row, number, err := domain.ParseSeatLabel(label)
+		if err != nil {
+			return nil, err
+		}
+		seatList[i] = domain.TicketSeat{ShowtimeID: showtimeID, Row: row, Number: number, Type: booked[i].Type, Price: booked[i].Price}
+	}
+
+	var theaterName string
+	if showtime.Theater != nil {
+		theaterName = showtime.Theater.Name
+	}
+
+	ticket := &domain.Ticket{
+		UserID:      userID,
+		MovieID:     showtime.MovieID,
+		ShowtimeID:  showtimeID,
+		Seats:       strings.Join(labels, ", "),
+		SeatList:    seatList,
+		CinemaName:  showtime.Cinema.Name,
+		TheaterName: theaterName,
+		Price:       total,
+		Status:      domain.StatusPendingPayment,
+	}
+	if err := s.createWithBookingCode(ticket); err != nil {
+		return nil, err
+	}
+
+	payment, err := s.Payments.StartPayment(ticket.ID, userID, total, fmt.Sprintf("Booking %s", ticket.BookingCode))
+	if err != nil {
+		// Without a payment nothing would ever expire the booking; a payment
+		// that was created and failed has released it already
+		if failErr := s.Repo.FailPayment(ticket.ID); failErr != nil {
+			return nil, failErr
+		}
+		return nil, err
+	}
+
+	return &dto.BookingResponse{
+		TicketID:    ticket.ID,
+		BookingCode: ticket.BookingCode,
+		ShowtimeID:  showtimeID,
+		Seats:       booked,
+		TotalPrice:  total,
+		Status:      ticket.Status,
+		Payment:     payment,
+	}, nil
+}
+
+// resolveSeats normalizes the requested seats ("g14 " -> "G14") and prices them
+// from the layout. Unknown or duplicate seats are rejected with ErrInvalidSeat;
+// seats that are not free for this viewer yield ErrSeatsUnavailable.
+func resolveSeats(layout *cinemaDto.SeatLayoutResponse, seats []string) ([]string, []dto.BookedSeat, float64, error) {
+	layoutSeats := make(map[string]int)
+	for i, seat := range layout.Layout.Seats {
+		layoutSeats[fmt.Sprintf("%s%d", seat.Row, seat.Number)] = i
+	}
+
+	labels := make([]string, 0, len(seats))
+	picked := make(map[string]bool)
+	var booked []dto.BookedSeat
+	var total float64
+	for _, raw := range seats {
+		label := strings.ToUpper(strings.TrimSpace(raw))
+		idx, ok := layoutSeats[label]
+		if !ok || picked[label] {
+			return nil, nil, 0, fmt.Errorf("%w: %s", domain.ErrInvalidSeat, raw)
+		}
+		seat := layout.Layout.Seats[idx]
+		// "selected" means the seat is held by this very viewer
+		if seat.Status != "available" && seat.Status != "selected" {
+			return nil, nil, 0, domain.ErrSeatsUnavailable
+		}
+
+		picked[label] = true
+		labels = append(labels, label)
+		rules := []string{}
+		for _, r := range layout.Pricing[seat.Type].Applied {
+			rules = append(rules, r.Name)
+		}
+		booked = append(booked, dto.BookedSeat{Seat: label, Type: seat.Type, Price: seat.Price, AppliedRules: rules})
+		total += seat.Price
+	}
+	return labels, booked, total, nil
+}
+
+// maxBookingCodeAttempts bounds retries on the (astronomically rare) event of
+// a random booking code colliding with an existing one.
+const maxBookingCodeAttempts = 5
+
+// createWithBookingCode books the ticket under a fresh random booking code,
+// drawing a new one if it is already taken.
+func (s *TicketService) createWithBookingCode(ticket *domain.Ticket) error {
+	for attempt := 1; ; attempt++ {
+		code, err := domain.NewBookingCode()
+		if err != nil {
+			return err
+		}
+		ticket.BookingCode = code
+
+		err = s.Repo.CreateBooking(ticket)
+		if !errors.Is(err, domain.ErrBookingCodeTaken) || attempt == maxBookingCodeAttempts {
+			return err
+		}
+	}
+}
+
+// CompleteFinishedTickets is a scheduler job: tickets become completed once
+// their showtime has ended.
+func (s *TicketService) CompleteFinishedTickets(now time.Time) (int64, error) {
+	return s.Repo.CompleteFinished(now)
+}
+
+// bookableShowtime returns the showtime, or ErrShowtimeStarted once it has
+// started and its seats can no longer be held or booked.
+func (s *TicketService) bookableShowtime(id int64) (*movieDomain.Showtime, error) {
+	showtime, err := s.MovieRepo.GetShowtimeByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !showtime.StartTime.After(time.Now()) {
+		return nil, domain.ErrShowtimeStarted
+	}
+	return showtime, nil
+}
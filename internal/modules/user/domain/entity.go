@@ -1,20 +1,68 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+	ErrInvalidRole         = errors.New("invalid role")
+)
+
+// Roles, from least to most privileged. Cinema staff are further limited to
+// the cinemas they are assigned to.
+const (
+	RoleCustomer    = "customer"
+	RoleCinemaStaff = "cinema_staff"
+	RoleChainAdmin  = "chain_admin"
+	RoleSuperAdmin  = "super_admin"
 )
 
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleCustomer, RoleCinemaStaff, RoleChainAdmin, RoleSuperAdmin:
+		return true
+	}
+	return false
+}
+
 type User struct {
 	ID       int64  `gorm:"primaryKey"`
 	Name     string `gorm:"not null;type:varchar(255)"`
 	Email    string `gorm:"not null;unique;type:varchar(255)"`
 	Password string `json:"-" gorm:"not null"`
+	Role     string `gorm:"not null;type:varchar(20);default:'customer'"`
 }
 
 type UserRepository interface {
 	GetByID(id int64) (*User, error)
 	GetByEmail(email string) (*User, error)
 	Create(user *User) error
+	UpdateRole(id int64, role string) error
+}
+
+// RefreshToken is a server-side session credential. Only the SHA-256 hash of
+// the token is stored. Every token rotated out of the same login shares a
+// FamilyID, so a whole session can be revoked at once.
+type RefreshToken struct {
+	ID        int64     `gorm:"primaryKey"`
+	UserID    int64     `gorm:"not null;index"`
+	FamilyID  string    `gorm:"not null;index;type:varchar(32)"`
+	TokenHash string    `gorm:"not null;uniqueIndex;type:varchar(64)"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	GetByHash(hash string) (*RefreshToken, error)
+	// Revoke marks a single token as revoked. It reports false if the token
+	// was already revoked, i.e. another request rotated it first.
+	Revoke(id int64) (bool, error)
+	RevokeFamily(familyID string) error
+	RevokeAllForUser(userID int64) error
 }
@@ -14,15 +14,26 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 type LoginResponse struct {
-	Token string        `json:"token"`
-	User  *UserResponse `json:"user"`
+	Token        string        `json:"token"`         // Short-lived access token
+	RefreshToken string        `json:"refresh_token"` // Single use, rotated on every refresh
+	ExpiresIn    int64         `json:"expires_in"`    // Access token lifetime in seconds
+	User         *UserResponse `json:"user"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=customer cinema_staff chain_admin super_admin"`
 }
 
 type UserResponse struct {
 	ID    int64  `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	Role  string `json:"role"`
 }
 
 func ToUserResponse(user *domain.User) *UserResponse {
@@ -30,5 +41,6 @@ func ToUserResponse(user *domain.User) *UserResponse {
 		ID:    user.ID,
 		Name:  user.Name,
 		Email: user.Email,
+		Role:  user.Role,
 	}
 }
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/dto"
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/service"
 	"github.com/go-playground/validator/v10"
@@ -18,13 +21,19 @@ func NewUserHandler(s *service.UserService, v *validator.Validate) *UserHandler
 	return &UserHandler{Service: s, Validator: v}
 }
 
-func (h *UserHandler) RegisterRoutes(app *fiber.App) {
+func (h *UserHandler) RegisterRoutes(app *fiber.App, authMiddleware fiber.Handler) {
 	auth := app.Group("/auth")
 	auth.Post("/register", h.handleRegister)
 	auth.Post("/login", h.handleLogin)
+	auth.Post("/refresh", h.handleRefresh)
+	auth.Post("/logout", h.handleLogout)
+	auth.Post("/logout-all", authMiddleware, h.handleLogoutAll)
 
-	users := app.Group("/users")
+	users := app.Group("/users", authMiddleware)
 	users.Get("/get", h.handleGetUser)
+
+	admin := app.Group("/admin/users", authMiddleware, middleware.RequireRoles(domain.RoleSuperAdmin))
+	admin.Put("/:id/role", h.handleUpdateRole)
 }
 
 func (h *UserHandler) handleLogin(c *fiber.Ctx) error {
@@ -46,6 +55,52 @@ func (h *UserHandler) handleLogin(c *fiber.Ctx) error {
 	return c.JSON(resp)
 }
 
+func (h *UserHandler) handleRefresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.Refresh(req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			return c.Status(fiber.StatusUnauthorized).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return c.JSON(resp)
+}
+
+func (h *UserHandler) handleLogout(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	if err := h.Service.Logout(req.RefreshToken); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *UserHandler) handleLogoutAll(c *fiber.Ctx) error {
+	if err := h.Service.LogoutAll(middleware.GetUserID(c)); err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
 func (h *UserHandler) handleRegister(c *fiber.Ctx) error {
 	var req dto.RegisterUserRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -78,3 +133,29 @@ func (h *UserHandler) handleGetUser(c *fiber.Ctx) error {
 
 	return c.JSON(dto.ToUserResponse(user))
 }
+
+func (h *UserHandler) handleUpdateRole(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	var req dto.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	if err := h.Validator.Struct(req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	user, err := h.Service.UpdateRole(id, req.Role)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return c.JSON(dto.ToUserResponse(user))
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
 	"gorm.io/gorm"
@@ -41,3 +42,60 @@ func (r *PostgresUserRepository) GetByEmail(email string) (*domain.User, error)
 func (r *PostgresUserRepository) Create(user *domain.User) error {
 	return r.DB.Create(user).Error
 }
+
+func (r *PostgresUserRepository) UpdateRole(id int64, role string) error {
+	result := r.DB.Model(&domain.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update user role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+type PostgresRefreshTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *gorm.DB) domain.RefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{DB: db}
+}
+
+func (r *PostgresRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *PostgresRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	if err := r.DB.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Revoke(id int64) (bool, error) {
+	// Conditional update so two concurrent refreshes cannot both rotate the token
+	result := r.DB.Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.DB.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeAllForUser(userID int64) error {
+	return r.DB.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -12,12 +16,21 @@ import (
 )
 
 type UserService struct {
-	Repo      domain.UserRepository
-	JWTSecret string
+	Repo            domain.UserRepository
+	TokenRepo       domain.RefreshTokenRepository
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 }
 
-func NewUserService(repo domain.UserRepository, jwtSecret string) *UserService {
-	return &UserService{Repo: repo, JWTSecret: jwtSecret}
+func NewUserService(repo domain.UserRepository, tokenRepo domain.RefreshTokenRepository, jwtSecret string, accessTokenTTL, refreshTokenTTL time.Duration) *UserService {
+	return &UserService{
+		Repo:            repo,
+		TokenRepo:       tokenRepo,
+		JWTSecret:       jwtSecret,
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: refreshTokenTTL,
+	}
 }
 
 func (s *UserService) GetUser(id int64) (*domain.User, error) {
@@ -38,6 +51,7 @@ func (s *UserService) RegisterUser(name, email, password, confirmPassword string
 		Name:     name,
 		Email:    email,
 		Password: string(hashedPassword),
+		Role:     domain.RoleCustomer,
 	}
 	err = s.Repo.Create(user)
 	if err != nil {
@@ -59,10 +73,101 @@ func (s *UserService) Login(email, password string) (*dto.LoginResponse, error)
 		return nil, errors.New("invalid credentials")
 	}
 
+	familyID, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, familyID)
+}
+
+// UpdateRole changes a user's role. The new role is picked up by the next
+// access token, i.e. after the user's next refresh.
+func (s *UserService) UpdateRole(id int64, role string) (*domain.User, error) {
+	if !domain.IsValidRole(role) {
+		return nil, domain.ErrInvalidRole
+	}
+	if err := s.Repo.UpdateRole(id, role); err != nil {
+		return nil, err
+	}
+	return s.Repo.GetByID(id)
+}
+
+// EnsureSuperAdmin promotes the user with the given email to super admin, so
+// a fresh deployment has someone who can hand out the other roles.
+func (s *UserService) EnsureSuperAdmin(email string) error {
+	user, err := s.Repo.GetByEmail(email)
+	if err != nil {
+		return err
+	}
+	if user.Role == domain.RoleSuperAdmin {
+		return nil
+	}
+	return s.Repo.UpdateRole(user.ID, domain.RoleSuperAdmin)
+}
+
+// Refresh rotates a refresh token: the presented token is revoked and a new
+// pair is issued in the same family. Presenting a token that was already
+// rotated out means it leaked, so the whole family is revoked.
+func (s *UserService) Refresh(refreshToken string) (*dto.LoginResponse, error) {
+	token, err := s.TokenRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RevokedAt != nil {
+		if err := s.TokenRepo.RevokeFamily(token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+	if time.Now().After(token.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	rotated, err := s.TokenRepo.Revoke(token.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// Lost a race against another refresh with the same token
+		if err := s.TokenRepo.RevokeFamily(token.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	user, err := s.Repo.GetByID(token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(user, token.FamilyID)
+}
+
+// Logout ends the session the refresh token belongs to. Unknown tokens are
+// ignored so logging out twice is harmless.
+func (s *UserService) Logout(refreshToken string) error {
+	token, err := s.TokenRepo.GetByHash(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) {
+			return nil
+		}
+		return err
+	}
+	return s.TokenRepo.RevokeFamily(token.FamilyID)
+}
+
+// LogoutAll revokes every session of the user. Access tokens already handed
+// out stay valid until they expire, which AccessTokenTTL keeps short.
+func (s *UserService) LogoutAll(userID int64) error {
+	return s.TokenRepo.RevokeAllForUser(userID)
+}
+
+func (s *UserService) issueTokens(user *domain.User, familyID string) (*dto.LoginResponse, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"email":   user.Email,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(),
+		"role":    user.Role,
+		"exp":     time.Now().Add(s.AccessTokenTTL).Unix(),
 	})
 
 	t, err := token.SignedString([]byte(s.JWTSecret))
@@ -70,8 +175,36 @@ func (s *UserService) Login(email, password string) (*dto.LoginResponse, error)
 		return nil, err
 	}
 
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.TokenRepo.Create(&domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.RefreshTokenTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
 	return &dto.LoginResponse{
-		Token: t,
-		User:  dto.ToUserResponse(user),
+		Token:        t,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.AccessTokenTTL.Seconds()),
+		User:         dto.ToUserResponse(user),
 	}, nil
 }
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
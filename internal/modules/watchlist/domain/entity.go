@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	userDomain "github.com/geraldiaditya/ratix-backend/internal/modules/user/domain"
+)
+
+var (
+	ErrAlertNotFound        = errors.New("on-sale alert not found")
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrAlreadyOnSale        = errors.New("tickets for the movie are already on sale in the city")
+	ErrUnknownCity          = errors.New("no cinema in the city")
+)
+
+// WatchlistItem is a movie a user keeps an eye on.
+type WatchlistItem struct {
+	UserID    int64             `gorm:"primaryKey" json:"user_id"`
+	MovieID   int64             `gorm:"primaryKey" json:"movie_id"`
+	Movie     movieDomain.Movie `gorm:"foreignKey:MovieID" json:"-"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// OnSaleAlert asks for a notification once the movie's first showtime is
+// scheduled at a cinema in City. It fires once.
+type OnSaleAlert struct {
+	ID        int64             `gorm:"primaryKey" json:"id"`
+	UserID    int64             `gorm:"not null;uniqueIndex:idx_on_sale_alerts_user_movie_city" json:"user_id"`
+	User      userDomain.User   `gorm:"foreignKey:UserID" json:"-"`
+	MovieID   int64             `gorm:"not null;uniqueIndex:idx_on_sale_alerts_user_movie_city" json:"movie_id"`
+	Movie     movieDomain.Movie `gorm:"foreignKey:MovieID" json:"-"`
+	City      string            `gorm:"type:varchar(100);not null;uniqueIndex:idx_on_sale_alerts_user_movie_city" json:"city"`
+	CreatedAt time.Time         `json:"created_at"`
+	// NotifiedAt is set once the user has been told; pending alerts have none
+	NotifiedAt *time.Time `gorm:"index" json:"notified_at"`
+}
+
+// Notification kinds.
+const (
+	KindTicketsOnSale = "tickets_on_sale"
+)
+
+// Notification is an in-app message; unread ones have no ReadAt.
+type Notification struct {
+	ID        int64      `gorm:"primaryKey" json:"id"`
+	UserID    int64      `gorm:"not null;index" json:"user_id"`
+	Kind      string     `gorm:"type:varchar(50);not null" json:"kind"`
+	MovieID   int64      `gorm:"not null" json:"movie_id"`
+	Title     string     `gorm:"type:varchar(255);not null" json:"title"`
+	Body      string     `gorm:"type:text;not null" json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Email is a message for an EmailSender.
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// EmailSender is implemented once per email provider.
+type EmailSender interface {
+	Name() string
+	Send(email Email) error
+}
+
+type WatchlistRepository interface {
+	// AddToWatchlist is a no-op when the movie is already on the list.
+	AddToWatchlist(userID, movieID int64) error
+	RemoveFromWatchlist(userID, movieID int64) error
+	// GetWatchlist returns the user's items with their Movie, latest first.
+	GetWatchlist(userID int64) ([]WatchlistItem, error)
+
+	// IsOnSale reports whether the movie has an upcoming showtime at a cinema
+	// in city.
+	IsOnSale(movieID int64, city string) (bool, error)
+	// CreateAlert returns the user's existing alert for the movie and city
+	// instead when there is one.
+	CreateAlert(alert *OnSaleAlert) error
+	GetAlerts(userID int64) ([]OnSaleAlert, error)
+	DeleteAlert(userID, id int64) error
+	// GetDueAlerts returns up to limit pending alerts whose movie has a
+	// showtime after now in their city, with their User and Movie.
+	GetDueAlerts(now time.Time, limit int) ([]OnSaleAlert, error)
+	// FireAlert marks the alert notified and stores the notification, unless
+	// the alert already fired. It reports whether it did.
+	FireAlert(alert *OnSaleAlert, notification *Notification) (bool, error)
+
+	GetNotifications(userID int64, unreadOnly bool, limit int) ([]Notification, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(userID, id int64) error
+}
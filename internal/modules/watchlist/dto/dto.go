@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+)
+
+type OnSaleAlertRequest struct {
+	MovieID int64  `json:"movie_id" validate:"required"`
+	City    string `json:"city" validate:"required,max=100"`
+}
+
+// WatchedMovie is the part of a movie the watchlist and alerts show.
+type WatchedMovie struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	PosterURL   string    `json:"poster_url"`
+	Status      string    `json:"status"`
+	ReleaseDate time.Time `json:"release_date"`
+}
+
+type WatchlistItemResponse struct {
+	Movie   WatchedMovie `json:"movie"`
+	AddedAt time.Time    `json:"added_at"`
+}
+
+type OnSaleAlertResponse struct {
+	ID         int64        `json:"id"`
+	Movie      WatchedMovie `json:"movie"`
+	City       string       `json:"city"`
+	CreatedAt  time.Time    `json:"created_at"`
+	NotifiedAt *time.Time   `json:"notified_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []domain.Notification `json:"notifications"`
+	UnreadCount   int64                 `json:"unread_count"`
+}
+
+func ToWatchedMovie(m movieDomain.Movie) WatchedMovie {
+	return WatchedMovie{
+		ID:          m.ID,
+		Title:       m.Title,
+		PosterURL:   m.PosterURL,
+		Status:      m.Status,
+		ReleaseDate: m.ReleaseDate,
+	}
+}
+
+func ToOnSaleAlertResponse(a domain.OnSaleAlert) OnSaleAlertResponse {
+	return OnSaleAlertResponse{
+		ID:         a.ID,
+		Movie:      ToWatchedMovie(a.Movie),
+		City:       a.City,
+		CreatedAt:  a.CreatedAt,
+		NotifiedAt: a.NotifiedAt,
+	}
+}
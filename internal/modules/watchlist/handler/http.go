@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/geraldiaditya/ratix-backend/internal/middleware"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WatchlistHandler struct {
+	Service   *service.WatchlistService
+	Validator *validator.Validate
+}
+
+func NewWatchlistHandler(s *service.WatchlistService, v *validator.Validate) *WatchlistHandler {
+	return &WatchlistHandler{Service: s, Validator: v}
+}
+
+func (h *WatchlistHandler) RegisterRoutes(app *fiber.App, auth fiber.Handler) {
+	watchlist := app.Group("/me/watchlist", auth)
+	watchlist.Get("/", h.handleGetWatchlist)
+	watchlist.Post("/:movieId", h.handleAddToWatchlist)
+	watchlist.Delete("/:movieId", h.handleRemoveFromWatchlist)
+
+	alerts := app.Group("/me/on-sale-alerts", auth)
+	alerts.Get("/", h.handleGetAlerts)
+	alerts.Post("/", h.handleSubscribe)
+	alerts.Delete("/:id", h.handleUnsubscribe)
+
+	notifications := app.Group("/me/notifications", auth)
+	notifications.Get("/", h.handleGetNotifications)
+	notifications.Post("/:id/read", h.handleMarkRead)
+}
+
+func (h *WatchlistHandler) handleGetWatchlist(c *fiber.Ctx) error {
+	resp, err := h.Service.GetWatchlist(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *WatchlistHandler) handleAddToWatchlist(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("movieId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.AddToWatchlist(middleware.GetUserID(c), movieID); err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WatchlistHandler) handleRemoveFromWatchlist(c *fiber.Ctx) error {
+	movieID, err := strconv.ParseInt(c.Params("movieId"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.RemoveFromWatchlist(middleware.GetUserID(c), movieID); err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WatchlistHandler) handleGetAlerts(c *fiber.Ctx) error {
+	resp, err := h.Service.GetAlerts(middleware.GetUserID(c))
+	if err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *WatchlistHandler) handleSubscribe(c *fiber.Ctx) error {
+	var req dto.OnSaleAlertRequest
+	if err := h.parseAndValidate(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	resp, err := h.Service.SubscribeOnSale(middleware.GetUserID(c), req)
+	if err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+func (h *WatchlistHandler) handleUnsubscribe(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.UnsubscribeOnSale(middleware.GetUserID(c), id); err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// handleGetNotifications lists the latest notifications, only unread ones
+// with ?unread=true, up to ?limit=.
+func (h *WatchlistHandler) handleGetNotifications(c *fiber.Ctx) error {
+	resp, err := h.Service.GetNotifications(middleware.GetUserID(c), c.QueryBool("unread"), c.QueryInt("limit"))
+	if err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.JSON(resp)
+}
+
+func (h *WatchlistHandler) handleMarkRead(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid ID")
+	}
+
+	if err := h.Service.MarkNotificationRead(middleware.GetUserID(c), id); err != nil {
+		return c.Status(watchlistErrorStatus(err)).SendString(err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *WatchlistHandler) parseAndValidate(c *fiber.Ctx, req interface{}) error {
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+	return h.Validator.Struct(req)
+}
+
+func watchlistErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, movieDomain.ErrMovieNotFound), errors.Is(err, domain.ErrAlertNotFound), errors.Is(err, domain.ErrNotificationNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrUnknownCity):
+		return fiber.StatusBadRequest
+	case errors.Is(err, domain.ErrAlreadyOnSale):
+		return fiber.StatusConflict
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PostgresWatchlistRepository struct {
+	DB *gorm.DB
+}
+
+func NewPostgresWatchlistRepository(db *gorm.DB) *PostgresWatchlistRepository {
+	return &PostgresWatchlistRepository{DB: db}
+}
+
+// onSaleCondition matches an upcoming showtime of the movie at a cinema in
+// the city; the arguments are the movie ID, the city and the current time.
+const onSaleCondition = `EXISTS (SELECT 1 FROM showtimes JOIN cinemas ON cinemas.id = showtimes.cinema_id
+	WHERE showtimes.movie_id = %s AND LOWER(cinemas.city) = LOWER(%s) AND showtimes.start_time > ?)`
+
+func (r *PostgresWatchlistRepository) AddToWatchlist(userID, movieID int64) error {
+	item := domain.WatchlistItem{UserID: userID, MovieID: movieID}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Omit(clause.Associations).Create(&item).Error
+}
+
+func (r *PostgresWatchlistRepository) RemoveFromWatchlist(userID, movieID int64) error {
+	return r.DB.Where("user_id = ? AND movie_id = ?", userID, movieID).Delete(&domain.WatchlistItem{}).Error
+}
+
+func (r *PostgresWatchlistRepository) GetWatchlist(userID int64) ([]domain.WatchlistItem, error) {
+	var items []domain.WatchlistItem
+	err := r.DB.Where("user_id = ?", userID).
+		Preload("Movie").
+		Order("created_at DESC, movie_id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *PostgresWatchlistRepository) IsOnSale(movieID int64, city string) (bool, error) {
+	var onSale bool
+	err := r.DB.Raw("SELECT "+fmt.Sprintf(onSaleCondition, "?", "?"), movieID, city, time.Now()).Scan(&onSale).Error
+	return onSale, err
+}
+
+func (r *PostgresWatchlistRepository) CreateAlert(alert *domain.OnSaleAlert) error {
+	err := r.DB.Omit(clause.Associations).Create(alert).Error
+	if isUniqueViolation(err) {
+		return r.DB.Where("user_id = ? AND movie_id = ? AND LOWER(city) = LOWER(?)", alert.UserID, alert.MovieID, alert.City).First(alert).Error
+	}
+	return err
+}
+
+func (r *PostgresWatchlistRepository) GetAlerts(userID int64) ([]domain.OnSaleAlert, error) {
+	var alerts []domain.OnSaleAlert
+	if err := r.DB.Where("user_id = ?", userID).Preload("Movie").Order("created_at DESC, id DESC").Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *PostgresWatchlistRepository) DeleteAlert(userID, id int64) error {
+	result := r.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&domain.OnSaleAlert{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrAlertNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWatchlistRepository) GetDueAlerts(now time.Time, limit int) ([]domain.OnSaleAlert, error) {
+	var alerts []domain.OnSaleAlert
+	err := r.DB.Where("notified_at IS NULL").
+		Where(fmt.Sprintf(onSaleCondition, "on_sale_alerts.movie_id", "on_sale_alerts.city"), now).
+		Preload("User").Preload("Movie").
+		Order("id").
+		Limit(limit).
+		Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *PostgresWatchlistRepository) FireAlert(alert *domain.OnSaleAlert, notification *domain.Notification) (bool, error) {
+	fired := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one replica gets to fire it
+		result := tx.Model(&domain.OnSaleAlert{}).
+			Where("id = ? AND notified_at IS NULL", alert.ID).
+			Update("notified_at", time.Now())
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		fired = true
+		return tx.Create(notification).Error
+	})
+	return fired, err
+}
+
+func (r *PostgresWatchlistRepository) GetNotifications(userID int64, unreadOnly bool, limit int) ([]domain.Notification, error) {
+	query := r.DB.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []domain.Notification
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *PostgresWatchlistRepository) CountUnread(userID int64) (int64, error) {
+	var count int64
+	err := r.DB.Model(&domain.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *PostgresWatchlistRepository) MarkRead(userID, id int64) error {
+	var notification domain.Notification
+	if err := r.DB.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotificationNotFound
+		}
+		return err
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return r.DB.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,30 @@
+// Package sender holds the EmailSender implementations.
+package sender
+
+import (
+	"log"
+
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+)
+
+// LogName is the provider name of LogSender.
+const LogName = "log"
+
+// LogSender is for development: emails are written to the server log instead
+// of being delivered.
+type LogSender struct {
+	From string
+}
+
+func NewLogSender(from string) *LogSender {
+	return &LogSender{From: from}
+}
+
+func (s *LogSender) Name() string {
+	return LogName
+}
+
+func (s *LogSender) Send(email domain.Email) error {
+	log.Printf("Email from %s to %s: %s\n%s", s.From, email.To, email.Subject, email.Body)
+	return nil
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	cinemaDomain "github.com/geraldiaditya/ratix-backend/internal/modules/cinema/domain"
+	movieDomain "github.com/geraldiaditya/ratix-backend/internal/modules/movie/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/domain"
+	"github.com/geraldiaditya/ratix-backend/internal/modules/watchlist/dto"
+	"github.com/geraldiaditya/ratix-backend/internal/pagination"
+)
+
+// alertBatchSize is how many due alerts a notify run loads at a time.
+const alertBatchSize = 100
+
+type WatchlistService struct {
+	Repo       domain.WatchlistRepository
+	MovieRepo  movieDomain.MovieRepository
+	CinemaRepo cinemaDomain.CinemaRepository
+	Sender     domain.EmailSender
+}
+
+func NewWatchlistService(repo domain.WatchlistRepository, movieRepo movieDomain.MovieRepository, cinemaRepo cinemaDomain.CinemaRepository, sender domain.EmailSender) *WatchlistService {
+	return &WatchlistService{Repo: repo, MovieRepo: movieRepo, CinemaRepo: cinemaRepo, Sender: sender}
+}
+
+// AddToWatchlist puts a movie on userID's watchlist; adding it twice is fine.
+func (s *WatchlistService) AddToWatchlist(userID, movieID int64) error {
+	if _, err := s.activeMovie(movieID); err != nil {
+		return err
+	}
+	return s.Repo.AddToWatchlist(userID, movieID)
+}
+
+func (s *WatchlistService) RemoveFromWatchlist(userID, movieID int64) error {
+	return s.Repo.RemoveFromWatchlist(userID, movieID)
+}
+
+// GetWatchlist lists userID's watchlist, latest first. Movies archived since
+// they were added are left out.
+func (s *WatchlistService) GetWatchlist(userID int64) ([]dto.WatchlistItemResponse, error) {
+	items, err := s.Repo.GetWatchlist(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []dto.WatchlistItemResponse{}
+	for _, item := range items {
+		if item.Movie.Status == movieDomain.StatusArchived {
+			continue
+		}
+		resp = append(resp, dto.WatchlistItemResponse{Movie: dto.ToWatchedMovie(item.Movie), AddedAt: item.CreatedAt})
+	}
+	return resp, nil
+}
+
+// SubscribeOnSale asks for a notification once the movie gets its first
+// showtime in the city, which must have a cinema. Subscribing again, in any
+// letter case, returns the existing alert.
+func (s *WatchlistService) SubscribeOnSale(userID int64, req dto.OnSaleAlertRequest) (*dto.OnSaleAlertResponse, error) {
+	city, err := s.knownCity(req.City)
+	if err != nil {
+		return nil, err
+	}
+	movie, err := s.activeMovie(req.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	onSale, err := s.Repo.IsOnSale(req.MovieID, city)
+	if err != nil {
+		return nil, err
+	}
+	if onSale {
+		return nil, domain.ErrAlreadyOnSale
+	}
+
+	alert := &domain.OnSaleAlert{UserID: userID, MovieID: req.MovieID, City: city}
+	if err := s.Repo.CreateAlert(alert); err != nil {
+		return nil, err
+	}
+	alert.Movie = *movie
+
+	resp := dto.ToOnSaleAlertResponse(*alert)
+	return &resp, nil
+}
+
+func (s *WatchlistService) GetAlerts(userID int64) ([]dto.OnSaleAlertResponse, error) {
+	alerts, err := s.Repo.GetAlerts(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []dto.OnSaleAlertResponse{}
+	for _, a := range alerts {
+		resp = append(resp, dto.ToOnSaleAlertResponse(a))
+	}
+	return resp, nil
+}
+
+func (s *WatchlistService) UnsubscribeOnSale(userID, alertID int64) error {
+	return s.Repo.DeleteAlert(userID, alertID)
+}
+
+// GetNotifications lists userID's latest notifications, only unread ones
+// when unreadOnly is set.
+func (s *WatchlistService) GetNotifications(userID int64, unreadOnly bool, limit int) (*dto.NotificationListResponse, error) {
+	notifications, err := s.Repo.GetNotifications(userID, unreadOnly, pagination.Limit(limit))
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.Repo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []domain.Notification{}
+	}
+	return &dto.NotificationListResponse{Notifications: notifications, UnreadCount: unread}, nil
+}
+
+func (s *WatchlistService) MarkNotificationRead(userID, id int64) error {
+	return s.Repo.MarkRead(userID, id)
+}
+
+// NotifyTicketsOnSale fires the alerts whose movie now has a showtime in
+// their city. The in-app notification is stored with the alert; the email is
+// best effort and a failed one is only logged.
+func (s *WatchlistService) NotifyTicketsOnSale(now time.Time) (int64, error) {
+	var notified int64
+	for {
+		alerts, err := s.Repo.GetDueAlerts(now, alertBatchSize)
+		if err != nil {
+			return notified, err
+		}
+
+		for i := range alerts {
+			alert := &alerts[i]
+			title := fmt.Sprintf("Tickets for %s are on sale", alert.Movie.Title)
+			body := fmt.Sprintf("Showtimes for %s have been scheduled in %s. Book your seats now.", alert.Movie.Title, alert.City)
+
+			fired, err := s.Repo.FireAlert(alert, &domain.Notification{
+				UserID:  alert.UserID,
+				Kind:    domain.KindTicketsOnSale,
+				MovieID: alert.MovieID,
+				Title:   title,
+				Body:    body,
+			})
+			if err != nil {
+				return notified, err
+			}
+			// Another replica got to it first
+			if !fired {
+				continue
+			}
+			notified++
+
+			if err := s.Sender.Send(domain.Email{To: alert.User.Email, Subject: title, Body: body}); err != nil {
+				log.Printf("Warning: Failed to email on-sale alert %d: %v", alert.ID, err)
+			}
+		}
+
+		if len(alerts) < alertBatchSize {
+			return notified, nil
+		}
+	}
+}
+
+// knownCity returns the city as the cinemas spell it, so alerts for one city
+// are stored under one name, or ErrUnknownCity when no cinema is there.
+func (s *WatchlistService) knownCity(city string) (string, error) {
+	city = strings.TrimSpace(city)
+	cities, err := s.CinemaRepo.GetAllCities()
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cities {
+		if strings.EqualFold(c.City, city) {
+			return c.City, nil
+		}
+	}
+	return "", domain.ErrUnknownCity
+}
+
+// activeMovie returns the movie, or ErrMovieNotFound when it is archived.
+func (s *WatchlistService) activeMovie(movieID int64) (*movieDomain.Movie, error) {
+	movie, err := s.MovieRepo.GetByID(movieID)
+	if err != nil {
+		return nil, err
+	}
+	if movie.Status == movieDomain.StatusArchived {
+		return nil, movieDomain.ErrMovieNotFound
+	}
+	return movie, nil
+}
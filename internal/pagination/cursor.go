@@ -0,0 +1,68 @@
+// Package pagination implements page/limit and keyset (cursor) pagination
+// for list endpoints.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 10
+	// MaxLimit caps every page, whatever the client asks for
+	MaxLimit = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Limit returns limit bounded to 1..MaxLimit, DefaultLimit when unset.
+func Limit(limit int) int {
+	switch {
+	case limit < 1:
+		return DefaultLimit
+	case limit > MaxLimit:
+		return MaxLimit
+	default:
+		return limit
+	}
+}
+
+// Cursor points at the row a page starts after, or ends before when
+// Backward. Clients only ever see it encoded.
+type Cursor struct {
+	Order    string `json:"o"` // Ordering it was issued for
+	Key      string `json:"k"` // The row's sort key, as text
+	ID       int64  `json:"i"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses an encoded cursor issued for order. An empty string is the
+// first page and decodes to nil.
+func Decode(s string, order Order) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Order != order.cursorName() {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Meta describes a page in cursor mode. Empty cursors mean there is nothing
+// more in that direction.
+type Meta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
@@ -0,0 +1,100 @@
+package pagination
+
+import (
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Order is a deterministic keyset ordering: by Key, then by the unique ID
+// column, both in the same direction.
+type Order struct {
+	Name    string // Identifies the ordering in cursors, e.g. "rating"
+	Key     string // SQL expression, never NULL
+	KeyType string // Postgres type of Key, e.g. "numeric" or "timestamptz"
+	ID      string // e.g. "movies.id"
+	Desc    bool
+}
+
+// cursorName ties cursors to the ordering and its direction, so a cursor
+// cannot be replayed against a different sort.
+func (o Order) cursorName() string {
+	if o.Desc {
+		return o.Name + ":desc"
+	}
+	return o.Name + ":asc"
+}
+
+// SQL is the ORDER BY clause for the ordering, for page/limit listings.
+func (o Order) SQL() string {
+	dir := "ASC"
+	if o.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", o.Key, dir, o.ID, dir)
+}
+
+// Keyset runs q for the page of limit rows after (or before) cursor, nil for
+// the first page, and returns their IDs in order with the page's cursors. No
+// COUNT is run; callers load the rows themselves, see Arrange.
+func Keyset(q *gorm.DB, order Order, cursor *Cursor, limit int) ([]int64, Meta, error) {
+	backward := cursor != nil && cursor.Backward
+	dir, cmp := "ASC", ">"
+	if order.Desc != backward {
+		dir, cmp = "DESC", "<"
+	}
+	if cursor != nil {
+		q = q.Where(fmt.Sprintf("(%s, %s) %s (CAST(? AS %s), ?)", order.Key, order.ID, cmp, order.KeyType), cursor.Key, cursor.ID)
+	}
+
+	var rows []struct {
+		ID      int64
+		SortKey string
+	}
+	err := q.Select(fmt.Sprintf("%s AS id, (%s)::text AS sort_key", order.ID, order.Key)).
+		Order(fmt.Sprintf("%s %s, %s %s", order.Key, dir, order.ID, dir)).
+		Limit(limit + 1).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	meta := Meta{Limit: limit}
+	if len(rows) == 0 {
+		return nil, meta, nil
+	}
+	// Paging one way from a cursor leaves rows the other way
+	first, last := rows[0], rows[len(rows)-1]
+	if (backward && more) || (!backward && cursor != nil) {
+		meta.PrevCursor = Cursor{Order: order.cursorName(), Key: first.SortKey, ID: first.ID, Backward: true}.Encode()
+	}
+	if (!backward && more) || backward {
+		meta.NextCursor = Cursor{Order: order.cursorName(), Key: last.SortKey, ID: last.ID}.Encode()
+	}
+
+	ids := make([]int64, len(rows))
+	for i, r := range rows {
+		ids[i] = r.ID
+	}
+	return ids, meta, nil
+}
+
+// Arrange orders items, loaded by the IDs Keyset returned, back into the
+// order of ids.
+func Arrange[T any](items []T, ids []int64, id func(T) int64) []T {
+	position := make(map[int64]int, len(ids))
+	for i, v := range ids {
+		position[v] = i
+	}
+	slices.SortFunc(items, func(a, b T) int { return position[id(a)] - position[id(b)] })
+	return items
+}